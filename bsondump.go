@@ -0,0 +1,164 @@
+package mongotest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// bsonMetadataSuffix is suffix of metadata file that mongodump writes with `<collection>.bson`.
+const bsonMetadataSuffix = ".metadata.json"
+
+// bsonMetadata is content of metadata file written by mongodump.
+type bsonMetadata struct {
+	Options bson.M   `bson:"options"`
+	Indexes []bson.D `bson:"indexes"`
+}
+
+// bsonDocReader reads documents from BSON file written by mongodump one by one.
+// Options and indexes in metadata file are read as `_options` and `_indexes` before documents.
+type bsonDocReader struct {
+	rc      io.ReadCloser
+	r       io.Reader
+	coll    string
+	entries []docEntry
+	count   int
+}
+
+func newBSONDocReader(rc io.ReadCloser, collName string, entries []docEntry) *bsonDocReader {
+	return &bsonDocReader{rc: rc, r: bufio.NewReader(rc), coll: collName, entries: entries}
+}
+
+// openBSONDocReader opens BSON file and its metadata file.
+func openBSONDocReader(f fixtureFile) (*bsonDocReader, error) {
+	entries, err := readBSONMetadata(bsonMetadataPath(f.path), f.collectionName())
+	if err != nil {
+		return nil, err
+	}
+	file, err := openFixtureFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	return newBSONDocReader(file, f.collectionName(), entries), nil
+}
+
+func (r *bsonDocReader) read() (string, string, DocData, error) {
+	if len(r.entries) > 0 {
+		e := r.entries[0]
+		r.entries = r.entries[1:]
+		return e.coll, e.key, e.doc, nil
+	}
+	raw, err := bson.NewFromIOReader(r.r)
+	if err == io.EOF {
+		return "", "", nil, io.EOF
+	}
+	r.count++
+	if err != nil {
+		return "", "", nil, &FixtureError{Collection: r.coll, Err: fmt.Errorf("document #%d: %w", r.count, err)}
+	}
+	var m map[string]interface{}
+	if err = bson.Unmarshal(raw, &m); err != nil {
+		return "", "", nil, &FixtureError{Collection: r.coll, Err: fmt.Errorf("document #%d: %w", r.count, err)}
+	}
+	doc := normalizeValue(m).(map[string]interface{})
+	id, ok := doc["_id"]
+	if !ok {
+		return "", "", nil, &FixtureError{Collection: r.coll, Err: fmt.Errorf("document #%d: no _id", r.count)}
+	}
+	doc["_id"] = exportedID{value: id}
+	return r.coll, documentKey(id), doc, nil
+}
+
+func (r *bsonDocReader) collections() []string {
+	return []string{r.coll}
+}
+
+func (r *bsonDocReader) Close() error {
+	return r.rc.Close()
+}
+
+// bsonMetadataPath returns path of metadata file of BSON file.
+// mongodump with --gzip compresses metadata file too, so compressed metadata file is used if exists.
+func bsonMetadataPath(file string) string {
+	path := strings.TrimSuffix(file, fixtureExt(file)) + bsonMetadataSuffix
+	if _, cext := splitCompressionExt(file); cext != "" {
+		if _, err := os.Stat(path + cext); err == nil {
+			return path + cext
+		}
+	}
+	return path
+}
+
+// readBSONMetadata reads metadata file and converts it into `_options` and `_indexes` entries.
+// Metadata file is optional, and options that are not supported by CollectionOptions are ignored.
+func readBSONMetadata(file, collName string) ([]docEntry, error) {
+	bs, err := readFixtureBytes(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var md bsonMetadata
+	if err = bson.UnmarshalExtJSON(bs, false, &md); err != nil {
+		return nil, &FixtureError{File: file, Collection: collName, Err: err}
+	}
+	var entries []docEntry
+	opts := make(DocData)
+	for k, v := range md.Options {
+		switch k {
+		case "validator", "validationLevel", "validationAction":
+			opts[k] = normalizeValue(v)
+		default:
+			warnf("option %s of collection %s is ignored (file: %s)", k, collName, file)
+		}
+	}
+	if len(opts) > 0 {
+		entries = append(entries, docEntry{coll: collName, key: optionsKey, doc: opts})
+	}
+	indexes := make(DocData)
+	for _, spec := range md.Indexes {
+		name, idx := toIndexSpec(spec)
+		if name != "_id_" {
+			indexes[name] = idx
+		}
+	}
+	if len(indexes) > 0 {
+		entries = append(entries, docEntry{coll: collName, key: indexesKey, doc: indexes})
+	}
+	return entries, nil
+}
+
+// toIndexSpec converts index specification in metadata into the form of `_indexes` in fixture.
+// Keys are converted into list of single field map for keeping order.
+func toIndexSpec(spec bson.D) (string, map[string]interface{}) {
+	var name string
+	idx := make(map[string]interface{}, len(spec))
+	for _, e := range spec {
+		switch e.Key {
+		case "v", "ns", "background":
+			// Version, namespace and background option are decided by server.
+		case "key":
+			keys, _ := e.Value.(bson.D)
+			kl := make([]interface{}, len(keys))
+			for i, k := range keys {
+				kl[i] = map[string]interface{}{k.Key: k.Value}
+			}
+			idx[e.Key] = kl
+		case "name":
+			name, _ = e.Value.(string)
+			idx[e.Key] = e.Value
+		default:
+			if d, ok := e.Value.(bson.D); ok {
+				idx[e.Key] = normalizeValue(d.Map())
+			} else {
+				idx[e.Key] = normalizeValue(e.Value)
+			}
+		}
+	}
+	return name, idx
+}
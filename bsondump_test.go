@@ -0,0 +1,99 @@
+package mongotest_test
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestUseFixtureBSONDump(t *testing.T) {
+	for _, d := range loadModes {
+		t.Run(d.name, func(t *testing.T) {
+			defer mongotest.Reconfigure(mongotest.Config{
+				Stream: d.stream,
+			})()
+			if err := mongotest.UseFixture("dump/mongotest"); err != nil {
+				t.Fatal(err)
+			}
+			oid, _ := primitive.ObjectIDFromHex("5c2cb0d0e7a0c1a1b2c3d4e5")
+			saved, err := mongotest.Find("users", "user1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]interface{}{
+				"_id":        "user1",
+				"name":       "user1",
+				"email":      "user1@example.com",
+				"company":    oid,
+				"created_at": createdAtPrimitive,
+			}
+			if !reflect.DeepEqual(saved, want) {
+				t.Errorf("saved user is invalid. (%v)", diffMap(saved, want))
+			}
+			cnt, err := mongotest.CountInt("companies")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cnt != 1 {
+				t.Errorf("saved company count is invalid (want: %d, got: %d)", 1, cnt)
+			}
+		})
+	}
+}
+
+func TestUseFixtureBSONDumpWithMetadata(t *testing.T) {
+	if err := mongotest.UseFixture("dump/mongotest/users"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, db := connectDatabase(t)
+	users := db.Collection("users")
+	if _, err := users.InsertOne(ctx, bson.M{"_id": "user3", "email": "user1@example.com"}); err == nil {
+		t.Error("unique index in metadata should be created")
+	}
+	if _, err := users.InsertOne(ctx, bson.M{"_id": "user4"}); err == nil {
+		t.Error("validator in metadata should be set")
+	}
+}
+
+func TestUseFixtureBSONDumpWithIndexMetadata(t *testing.T) {
+	if err := mongotest.UseFixture("dump/mongotest/companies"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, db := connectDatabase(t)
+	cur, err := db.Collection("companies").Indexes().List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var specs []bson.M
+	if err = cur.All(ctx, &specs); err != nil {
+		t.Fatal(err)
+	}
+	indexes := make(map[string]bson.M, len(specs))
+	for _, spec := range specs {
+		indexes[spec["name"].(string)] = spec
+	}
+	if idx, ok := indexes["name_text_note_text"]; !ok {
+		t.Error("text index should be created")
+	} else if idx["language_override"] != "language" || idx["textIndexVersion"] != int32(3) {
+		t.Errorf("text index options are invalid. (%v)", idx)
+	}
+	if idx, ok := indexes["name_1"]; !ok {
+		t.Error("index with collation should be created")
+	} else if coll, _ := idx["collation"].(bson.M); coll == nil || coll["locale"] != "en" || coll["strength"] != int32(2) {
+		t.Errorf("collation of index is invalid. (%v)", idx)
+	}
+	if idx, ok := indexes["location_2d"]; !ok {
+		t.Error("2d index should be created")
+	} else if idx["bits"] != int32(26) || idx["min"] != -180.0 || idx["max"] != 180.0 {
+		t.Errorf("2d index options are invalid. (%v)", idx)
+	}
+	if idx, ok := indexes["code_1"]; !ok {
+		t.Error("hidden index should be created")
+	} else if idx["hidden"] != true {
+		t.Errorf("hidden index option is invalid. (%v)", idx)
+	}
+}
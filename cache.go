@@ -0,0 +1,217 @@
+package mongotest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// maxCacheBytes is max total size of cached fixture files. Least recently used entries are evicted.
+	maxCacheBytes = 64 << 20
+	// modTimeGranularity is granularity of modification time of file systems. (e.g. 2 seconds in FAT)
+	// File modified within the granularity after caching may have same modification time, so its contents are compared.
+	modTimeGranularity = 2 * time.Second
+)
+
+// fixtureCache holds parsed fixture files.
+// Cached DataSet is used while modification time and size of file are not changed.
+// Contents are compared by hash only when modification time is too close to the time of caching.
+var fixtureCache = struct {
+	sync.Mutex
+	entries map[string]*cacheEntry
+	size    int64
+}{entries: make(map[string]*cacheEntry)}
+
+type cacheEntry struct {
+	stats    []fileStat
+	hash     string
+	cachedAt time.Time
+	usedAt   time.Time
+	ds       DataSet
+	order    dataOrder
+}
+
+// fileStat is modification time and size of file. Zero value means that file does not exist.
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+func (e *cacheEntry) size() int64 {
+	var n int64
+	for _, st := range e.stats {
+		n += st.size
+	}
+	return n
+}
+
+// isFresh reports whether entry can be used without reading file.
+func (e *cacheEntry) isFresh(stats []fileStat) bool {
+	if len(stats) != len(e.stats) {
+		return false
+	}
+	for i, st := range stats {
+		if !st.modTime.Equal(e.stats[i].modTime) || st.size != e.stats[i].size {
+			return false
+		}
+		if !st.modTime.Before(e.cachedAt.Add(-modTimeGranularity)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ClearFixtureCache removes all parsed fixtures from cache.
+func ClearFixtureCache() {
+	fixtureCache.Lock()
+	defer fixtureCache.Unlock()
+	fixtureCache.entries = make(map[string]*cacheEntry)
+	fixtureCache.size = 0
+}
+
+// loadFixtureFile returns DataSet of given fixture file using cache.
+// Returned DataSet is a copy, so caller can modify it.
+// Cache is not used in Stream, because cached DataSet is held in memory.
+func loadFixtureFile(f fixtureFile) (DataSet, dataOrder, error) {
+	if conf.DisableCache || conf.Stream {
+		return readFixtureFile(f)
+	}
+	format, err := fixtureFormat(f.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	paths := []string{f.path}
+	if format == FixtureFormatBSON {
+		paths = append(paths, bsonMetadataPath(f.path))
+	}
+	stats, err := fileStats(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := string(format) + ":" + f.collection + ":" + f.path
+	fixtureCache.Lock()
+	e, ok := fixtureCache.entries[key]
+	if ok && e.isFresh(stats) {
+		e.usedAt = time.Now()
+		fixtureCache.Unlock()
+		return copyDataSet(e.ds), e.order, nil
+	}
+	fixtureCache.Unlock()
+	cachedAt := time.Now()
+	hash, err := fileHash(paths...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok && e.hash == hash {
+		fixtureCache.Lock()
+		e.stats, e.cachedAt, e.usedAt = stats, cachedAt, cachedAt
+		fixtureCache.Unlock()
+		return copyDataSet(e.ds), e.order, nil
+	}
+	ds, order, err := readFixtureFile(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	putCacheEntry(key, &cacheEntry{stats: stats, hash: hash, cachedAt: cachedAt, usedAt: cachedAt, ds: copyDataSet(ds), order: order})
+	return ds, order, nil
+}
+
+// putCacheEntry stores entry with evicting least recently used entries to keep total size under limit.
+// Entry of file larger than limit is not stored.
+func putCacheEntry(key string, e *cacheEntry) {
+	fixtureCache.Lock()
+	defer fixtureCache.Unlock()
+	if old, ok := fixtureCache.entries[key]; ok {
+		fixtureCache.size -= old.size()
+		delete(fixtureCache.entries, key)
+	}
+	if e.size() > maxCacheBytes {
+		return
+	}
+	for fixtureCache.size+e.size() > maxCacheBytes {
+		var lruKey string
+		var lru *cacheEntry
+		for k, v := range fixtureCache.entries {
+			if lru == nil || v.usedAt.Before(lru.usedAt) {
+				lruKey, lru = k, v
+			}
+		}
+		fixtureCache.size -= lru.size()
+		delete(fixtureCache.entries, lruKey)
+	}
+	fixtureCache.entries[key] = e
+	fixtureCache.size += e.size()
+}
+
+// fileStats returns stats of given files.
+// Files except the first are optional (e.g. metadata file of BSON), and zero value is returned when they do not exist.
+func fileStats(paths []string) ([]fileStat, error) {
+	stats := make([]fileStat, len(paths))
+	for i, p := range paths {
+		fi, err := os.Stat(p)
+		if i > 0 && os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		stats[i] = fileStat{modTime: fi.ModTime(), size: fi.Size()}
+	}
+	return stats, nil
+}
+
+// fileHash returns SHA-256 hash of contents of given files.
+// Files except the first are optional (e.g. metadata file of BSON), and skipped when they do not exist.
+func fileHash(paths ...string) (string, error) {
+	h := sha256.New()
+	for i, p := range paths {
+		file, err := os.Open(p)
+		if i > 0 && os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(h, file)
+		file.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func copyDataSet(ds DataSet) DataSet {
+	copied := make(DataSet, len(ds))
+	for cn, cd := range ds {
+		cc := make(CollectionData, len(cd))
+		for key, doc := range cd {
+			cc[key] = copyValue(map[string]interface{}(doc)).(map[string]interface{})
+		}
+		copied[cn] = cc
+	}
+	return copied
+}
+
+func copyValue(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(tv))
+		for k, v := range tv {
+			m[k] = copyValue(v)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(tv))
+		for i, v := range tv {
+			a[i] = copyValue(v)
+		}
+		return a
+	default:
+		return v
+	}
+}
@@ -0,0 +1,100 @@
+package mongotest_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestUseFixtureWithModifiedCachedFile(t *testing.T) {
+	dir := t.TempDir()
+	defer mongotest.Reconfigure(mongotest.Config{
+		FixtureRootDir: dir,
+	})()
+	file := filepath.Join(dir, "users.yml")
+	fixtures := []string{
+		"users:\n  user1:\n    name: user1\n",
+		"users:\n  user1:\n    name: user1\n  user2:\n    name: user2\n",
+	}
+	for i, fixture := range fixtures {
+		if err := ioutil.WriteFile(file, []byte(fixture), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := mongotest.UseFixture("users"); err != nil {
+			t.Fatal(err)
+		}
+		cnt, err := mongotest.CountInt("users")
+		if err != nil {
+			t.Error(err)
+		}
+		if cnt != i+1 {
+			t.Errorf("modified fixture should be reloaded (want: %d, got: %d)", i+1, cnt)
+		}
+	}
+}
+
+func TestUseFixtureWithModifiedCachedFileInSameTime(t *testing.T) {
+	dir := t.TempDir()
+	defer mongotest.Reconfigure(mongotest.Config{
+		FixtureRootDir: dir,
+	})()
+	file := filepath.Join(dir, "users.yml")
+	// Fixtures have same size and same modification time in granularity of second.
+	modTime := time.Now().Truncate(time.Second)
+	for _, key := range []string{"user1", "user2"} {
+		if err := ioutil.WriteFile(file, []byte("users:\n  "+key+":\n    name: "+key+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		if err := mongotest.UseFixture("users"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := mongotest.Find("users", key); err != nil {
+			t.Errorf("modified fixture should be reloaded (%s)", err)
+		}
+	}
+}
+
+func TestUseFixtureWithCachedFile(t *testing.T) {
+	mongotest.ClearFixtureCache()
+	for i := 0; i < 2; i++ {
+		if err := mongotest.UseFixture("refs/users"); err != nil {
+			t.Fatal(err)
+		}
+		saved, err := mongotest.Find("users", "user1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := saved["company"]; got != "foo" {
+			t.Errorf("cached fixture should not be modified by loading (want: %q, got: %v)", "foo", got)
+		}
+	}
+}
+
+func TestUseFixtureWithDisableCache(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		DisableCache: true,
+	})()
+	if err := mongotest.UseFixture("admin_users"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUseFixtureWithStreamDoesNotCache(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		Stream: true,
+	})()
+	mongotest.ClearFixtureCache()
+	if err := mongotest.UseFixture("admin_users"); err != nil {
+		t.Fatal(err)
+	}
+	if n := mongotest.FixtureCacheLen(); n != 0 {
+		t.Errorf("fixture should not be cached in Stream (got: %d entries)", n)
+	}
+}
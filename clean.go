@@ -0,0 +1,76 @@
+package mongotest
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CleanModeType is mode of cleaning collections that are not contained in fixture.
+type CleanModeType string
+
+const (
+	// CleanModeNone means that collections not contained in fixture are kept. (default)
+	CleanModeNone = CleanModeType("None")
+	// CleanModeTruncate means that all documents in collections not contained in fixture are deleted.
+	CleanModeTruncate = CleanModeType("Truncate")
+	// CleanModeDrop means that collections not contained in fixture are dropped.
+	CleanModeDrop  = CleanModeType("Drop")
+	cleanModeEmpty = CleanModeType("")
+)
+
+func validateCleanMode(mode CleanModeType) error {
+	switch mode {
+	case CleanModeNone, CleanModeTruncate, CleanModeDrop:
+		return nil
+	default:
+		return fmt.Errorf("%w: invalid CleanMode %q", ErrInvalidConfig, mode)
+	}
+}
+
+// cleanCollections truncates or drops collections that are not contained in given names.
+// System collections, snapshot collections and collections matched with CleanExcludes are kept.
+func cleanCollections(ctx context.Context, mode CleanModeType, loaded map[string]bool) error {
+	if mode == CleanModeNone {
+		return nil
+	}
+	ctx, client, cancel, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	db := client.Database(conf.Database)
+	names, err := db.ListCollectionNames(ctx, bson.M{"type": "collection"})
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if loaded[name] || isCleanExcluded(name) {
+			continue
+		}
+		if mode == CleanModeDrop {
+			err = db.Collection(name).Drop(ctx)
+		} else {
+			_, err = db.Collection(name).DeleteMany(ctx, bson.M{})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isCleanExcluded(name string) bool {
+	if strings.HasPrefix(name, "system.") || strings.HasPrefix(name, snapshotPrefix) {
+		return true
+	}
+	for _, pattern := range conf.CleanExcludes {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package mongotest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestUseFixtureWithCleanMode(t *testing.T) {
+	testdata := []struct {
+		mode     mongotest.CleanModeType
+		excludes []string
+		count    int
+	}{
+		{mode: mongotest.CleanModeNone, count: 2},
+		{mode: mongotest.CleanModeTruncate, count: 0},
+		{mode: mongotest.CleanModeDrop, count: 0},
+		{mode: mongotest.CleanModeTruncate, excludes: []string{"compan*"}, count: 2},
+	}
+	for _, d := range testdata {
+		t.Run(string(d.mode), func(t *testing.T) {
+			defer mongotest.Reconfigure(mongotest.Config{
+				CleanExcludes: d.excludes,
+			})()
+			err := mongotest.UseFixture("admin_users")
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := mongotest.LoadOptions{CleanMode: d.mode}
+			err = mongotest.UseFixtureWithOptions(context.Background(), opts, "modes/more_users")
+			if err != nil {
+				t.Fatal(err)
+			}
+			cnt, err := mongotest.CountInt("companies")
+			if err != nil {
+				t.Error(err)
+			}
+			if cnt != d.count {
+				t.Errorf("company count is invalid (want: %d, got: %d)", d.count, cnt)
+			}
+		})
+	}
+}
+
+func TestUseFixtureWithInvalidCleanMode(t *testing.T) {
+	opts := mongotest.LoadOptions{CleanMode: mongotest.CleanModeType("Unknown")}
+	err := mongotest.UseFixtureWithOptions(context.Background(), opts, "admin_users")
+	if err == nil {
+		t.Error("should error when clean mode is invalid")
+	}
+}
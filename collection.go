@@ -0,0 +1,136 @@
+package mongotest
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// optionsKey is reserved key of collection options in fixture.
+//
+//	users:
+//	  _options:
+//	    validator:
+//	      $jsonSchema: ...
+//	    validationLevel: strict
+//	    validationAction: error
+//	    order: key
+const optionsKey = "_options"
+
+// CollectionOptions is options for creating collection.
+type CollectionOptions struct {
+	// Validator is validator of collection. (e.g. `{"$jsonSchema": {...}}`)
+	Validator interface{}
+	// ValidationLevel is validation level of collection. (off, strict or moderate)
+	ValidationLevel string
+	// ValidationAction is validation action of collection. (error or warn)
+	ValidationAction string
+	// Indexes are indexes created after inserting documents.
+	Indexes []mongo.IndexModel
+	// DocumentOrder is order of inserting documents. If empty, configured DocumentOrder is used.
+	DocumentOrder DocumentOrderType
+}
+
+func (o CollectionOptions) documentOrder() DocumentOrderType {
+	if o.DocumentOrder == documentOrderEmpty {
+		return conf.DocumentOrder
+	}
+	return o.DocumentOrder
+}
+
+// needsCreate returns true when collection should be created explicitly.
+func (o CollectionOptions) needsCreate() bool {
+	return o.Validator != nil || o.ValidationLevel != "" || o.ValidationAction != ""
+}
+
+// merge returns options overwritten by non-empty values of given options.
+func (o CollectionOptions) merge(o2 CollectionOptions) CollectionOptions {
+	merged := o
+	if o2.Validator != nil {
+		merged.Validator = o2.Validator
+	}
+	if o2.ValidationLevel != "" {
+		merged.ValidationLevel = o2.ValidationLevel
+	}
+	if o2.ValidationAction != "" {
+		merged.ValidationAction = o2.ValidationAction
+	}
+	if o2.Indexes != nil {
+		merged.Indexes = o2.Indexes
+	}
+	if o2.DocumentOrder != documentOrderEmpty {
+		merged.DocumentOrder = o2.DocumentOrder
+	}
+	return merged
+}
+
+func (o CollectionOptions) createOptions() *options.CreateCollectionOptions {
+	opts := options.CreateCollection()
+	if o.Validator != nil {
+		opts.SetValidator(o.Validator)
+	}
+	if o.ValidationLevel != "" {
+		opts.SetValidationLevel(o.ValidationLevel)
+	}
+	if o.ValidationAction != "" {
+		opts.SetValidationAction(o.ValidationAction)
+	}
+	return opts
+}
+
+// extractCollectionOptions removes options and indexes entries from each collection data in DataSet,
+// and returns options merged with configured options.
+func extractCollectionOptions(ds DataSet, srcs dataSources) (map[string]CollectionOptions, error) {
+	optsMap := make(map[string]CollectionOptions, len(ds))
+	var errs MultiError
+	for cn, cd := range ds {
+		opts := conf.CollectionOptions[cn]
+		if doc, ok := cd[optionsKey]; ok {
+			delete(cd, optionsKey)
+			fo, err := toCollectionOptions(doc)
+			if err != nil {
+				errs = errs.add(withLocation(err, srcs[cn][optionsKey], cn, optionsKey))
+			}
+			opts = opts.merge(fo)
+		}
+		indexes, err := extractIndexes(cd)
+		if err != nil {
+			errs = errs.add(withLocation(err, srcs[cn][indexesKey], cn, indexesKey))
+		}
+		opts = opts.merge(CollectionOptions{Indexes: indexes})
+		optsMap[cn] = opts
+	}
+	return optsMap, errs.err()
+}
+
+func toCollectionOptions(doc DocData) (CollectionOptions, error) {
+	var opts CollectionOptions
+	for k, v := range doc {
+		switch k {
+		case "validator":
+			opts.Validator = v
+		case "validationLevel":
+			s, ok := v.(string)
+			if !ok {
+				return opts, fmt.Errorf("invalid validationLevel: %v", v)
+			}
+			opts.ValidationLevel = s
+		case "validationAction":
+			s, ok := v.(string)
+			if !ok {
+				return opts, fmt.Errorf("invalid validationAction: %v", v)
+			}
+			opts.ValidationAction = s
+		case "order":
+			order, err := toDocumentOrder(v)
+			if err != nil {
+				return opts, err
+			}
+			opts.DocumentOrder = order
+		default:
+			return opts, fmt.Errorf("unknown collection option %q", k)
+		}
+	}
+	return opts, nil
+}
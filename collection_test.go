@@ -0,0 +1,45 @@
+package mongotest_test
+
+import (
+	"testing"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestUseFixtureWithCollectionOptions(t *testing.T) {
+	err := mongotest.UseFixture("options/valid_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cnt, err := mongotest.CountInt("users")
+	if err != nil {
+		t.Error(err)
+	}
+	if cnt != 1 {
+		t.Errorf("saved user count is invalid (want: %d, got: %d)", 1, cnt)
+	}
+
+	err = mongotest.UseFixture("options/invalid_users")
+	if err == nil {
+		t.Error("should error when document violates collection validator")
+	}
+}
+
+func TestUseFixtureWithConfiguredCollectionOptions(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		CollectionOptions: map[string]mongotest.CollectionOptions{
+			"users": {
+				Validator: map[string]interface{}{
+					"$jsonSchema": map[string]interface{}{
+						"bsonType": "object",
+						"required": []string{"phone"},
+					},
+				},
+			},
+		},
+	})()
+	err := mongotest.UseFixture("admin_users")
+	if err == nil {
+		t.Error("should error when document violates configured collection validator")
+	}
+}
@@ -0,0 +1,84 @@
+package mongotest
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// gzipExt is suffix of gzip compressed fixture. (e.g. `users.json.gz`)
+	gzipExt = ".gz"
+	// zstdExt is suffix of zstd compressed fixture. (e.g. `users.ndjson.zst`)
+	zstdExt = ".zst"
+)
+
+// splitCompressionExt returns file name without compression suffix and the suffix.
+// Suffix is empty when file is not compressed.
+func splitCompressionExt(file string) (string, string) {
+	ext := filepath.Ext(file)
+	switch strings.ToLower(ext) {
+	case gzipExt, zstdExt:
+		return strings.TrimSuffix(file, ext), ext
+	default:
+		return file, ""
+	}
+}
+
+// openFixtureFile opens fixture file with decompressing it transparently.
+func openFixtureFile(file string) (io.ReadCloser, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	_, ext := splitCompressionExt(file)
+	switch strings.ToLower(ext) {
+	case gzipExt:
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &decompressReader{Reader: zr, close: func() error {
+			zr.Close()
+			return f.Close()
+		}}, nil
+	case zstdExt:
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &decompressReader{Reader: zr, close: func() error {
+			zr.Close()
+			return f.Close()
+		}}, nil
+	default:
+		return f, nil
+	}
+}
+
+// readFixtureBytes reads all decompressed bytes of fixture file.
+func readFixtureBytes(file string) ([]byte, error) {
+	rc, err := openFixtureFile(file)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+// decompressReader closes both decompressor and underlying file.
+type decompressReader struct {
+	io.Reader
+	close func() error
+}
+
+func (r *decompressReader) Close() error {
+	return r.close()
+}
@@ -0,0 +1,73 @@
+package mongotest_test
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestUseFixtureCompressed(t *testing.T) {
+	testdata := []struct {
+		name string
+		age  interface{}
+	}{
+		{name: "compressed/admin_users", age: int32(30)},
+		{name: "compressed/json_admin_users", age: float64(30)},
+	}
+	for _, d := range testdata {
+		t.Run(d.name, func(t *testing.T) {
+			err := mongotest.UseFixture(d.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			saved, err := mongotest.Find("users", "admin1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]interface{}{
+				"_id":        "admin1",
+				"name":       "admin user1",
+				"email":      "admin1@example.com",
+				"admin":      true,
+				"company":    "foo",
+				"age":        d.age,
+				"note":       "abc",
+				"created_at": createdAtPrimitive,
+			}
+			if !reflect.DeepEqual(saved, want) {
+				t.Errorf("saved user is invalid. (%v)", diffMap(saved, want))
+			}
+		})
+	}
+}
+
+func TestUseFixtureCompressedNDJSON(t *testing.T) {
+	for _, d := range loadModes {
+		t.Run(d.name, func(t *testing.T) {
+			defer mongotest.Reconfigure(mongotest.Config{
+				Stream: d.stream,
+			})()
+			if err := mongotest.UseFixture("compressed/users"); err != nil {
+				t.Fatal(err)
+			}
+			oid, _ := primitive.ObjectIDFromHex("5c2cb0d0e7a0c1a1b2c3d4e5")
+			want := []interface{}{oid, "user2"}
+			if got := naturalOrderUserIDs(t); !reflect.DeepEqual(got, want) {
+				t.Errorf("documents of zstd compressed fixture should be loaded (want: %v, got: %v)", want, got)
+			}
+		})
+	}
+}
+
+func TestUseFixtureCompressedBSONDump(t *testing.T) {
+	if err := mongotest.UseFixture("compressed/dump"); err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{"user1", "user2"}
+	if got := naturalOrderUserIDs(t); !reflect.DeepEqual(got, want) {
+		t.Errorf("documents of gzip compressed dump should be loaded (want: %v, got: %v)", want, got)
+	}
+}
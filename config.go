@@ -1,8 +1,9 @@
 package mongotest
 
 import (
-	"errors"
+	"fmt"
 	"path/filepath"
+	"strings"
 )
 
 // FixtureFormatType is decision policy of fixture data format.
@@ -15,12 +16,44 @@ const (
 	FixtureFormatJSON = FixtureFormatType("JSON")
 	// FixtureFormatYAML means that fixture is written with YAML format.
 	FixtureFormatYAML = FixtureFormatType("YAML")
+	// FixtureFormatTOML means that fixture is written with TOML format.
+	FixtureFormatTOML = FixtureFormatType("TOML")
+	// FixtureFormatCSV means that fixture is written with CSV format.
+	// CSV fixture holds documents of a collection named by the file.
+	FixtureFormatCSV = FixtureFormatType("CSV")
+	// FixtureFormatTSV means that fixture is written with TSV format.
+	// TSV fixture holds documents of a collection named by the file.
+	FixtureFormatTSV = FixtureFormatType("TSV")
+	// FixtureFormatNDJSON means that fixture is written with newline-delimited Extended JSON like output of mongoexport.
+	// NDJSON fixture holds documents of a collection named by the file.
+	FixtureFormatNDJSON = FixtureFormatType("NDJSON")
+	// FixtureFormatBSON means that fixture is BSON file written by mongodump.
+	// BSON fixture holds documents of a collection named by the file,
+	// and options and indexes are read from `<collection>.metadata.json` if exists.
+	FixtureFormatBSON = FixtureFormatType("BSON")
 	// fixtureFormatUnknown means that fixture is written with unknown format.
 	// Not export this value. Using error instead of this value.
 	fixtureFormatUnknown = FixtureFormatType("Unknown")
 	fixtureFormatEmpty   = FixtureFormatType("")
 
 	defaultTimeoutSeconds = 10
+	defaultWorkers        = 1
+)
+
+// LoadModeType is mode of loading fixture into collection.
+type LoadModeType string
+
+const (
+	// LoadModeDrop means that collection is dropped before inserting. (default)
+	LoadModeDrop = LoadModeType("Drop")
+	// LoadModeTruncate means that all documents are deleted before inserting.
+	// Collection metadata like indexes and validators are kept.
+	LoadModeTruncate = LoadModeType("Truncate")
+	// LoadModeUpsert means that documents are replaced by _id, or inserted if not exist.
+	LoadModeUpsert = LoadModeType("Upsert")
+	// LoadModeAppend means that documents are inserted into existing collection.
+	LoadModeAppend = LoadModeType("Append")
+	loadModeEmpty  = LoadModeType("")
 )
 
 // Config is configuration holder of mongotest module.
@@ -29,10 +62,64 @@ type Config struct {
 	Database       string
 	FixtureRootDir string
 	FixtureFormat  FixtureFormatType
-	Timeout        int
-	PreInsertFuncs []PreInsertFunc
+	// FixtureExtensions is priority list of file extensions (e.g. `.yml`, `.json`, `.json.gz`) used when fixture name matches multiple files.
+	// Files that have other extensions are ignored when configured.
+	// When not configured, fixture name that matches multiple files is error.
+	FixtureExtensions []string
+	Timeout           int
+	PreInsertFuncs    []PreInsertFunc
+	LoadMode          LoadModeType
+	// DocumentOrder is order of inserting documents. (default DocumentOrderFile)
+	// It can be overwritten per collection with CollectionOptions or `order` in `_options` of fixture.
+	DocumentOrder DocumentOrderType
+	// CleanMode is mode of cleaning collections that are not contained in fixture.
+	CleanMode CleanModeType
+	// CleanExcludes are name patterns of collections that are never cleaned. (e.g. `migrations`, `schema_*`)
+	// System collections (`system.*`) and snapshot collections (`mongotest_snapshot.*`) are always excluded.
+	CleanExcludes []string
+	// Transaction means that all collections are loaded in a multi-document transaction.
+	// Transaction requires replica set or sharded cluster, fixture is loaded without transaction on standalone server.
+	// In transaction, collections are not dropped but truncated even if LoadMode is LoadModeDrop.
+	Transaction bool
+	// Workers is max number of collections loaded concurrently. (default 1)
+	// Workers is ignored when fixture is loaded in transaction.
+	Workers int
+	// BatchSize is max number of documents written in a request. (default 0: all documents of collection)
+	BatchSize int
+	// UnorderedInsert means that documents are written with unordered bulk operation.
+	// Unordered operation continues writing remaining documents after an error.
+	UnorderedInsert bool
+	// Stream means that documents are inserted incrementally while decoding fixture files.
+	// Whole DataSet is not built in memory, so fixtures are not merged (same collection is loaded once and appended),
+	// references and relations are not resolved, and Workers and Transaction are ignored.
+	// Only JSON, NDJSON and BSON files are decoded incrementally, and YAML, TOML and CSV files are decoded at once per file.
+	// Timeout is applied to each batch instead of whole loading.
+	Stream bool
+	// DisableCache means that fixture files are read and parsed on every load.
+	// By default, parsed fixtures are cached while modification time and size of file are not changed.
+	// Cache is not used in Stream.
+	DisableCache bool
+	// SkipUnchanged means that collections loaded with same fixture and not modified after loading are not reloaded.
+	// Modification is detected with dbHash command, so this is not available on mongos.
+	// Change streams need replica set and command monitoring cannot observe writes of other clients (e.g. tested server),
+	// so dbHash is used in spite of that it takes lock of database while hashing.
+	// This is ignored in LoadModeAppend and Stream.
+	SkipUnchanged bool
+	// Relations are relations between collections that are validated before inserting.
+	// Relation is written as `<collection>.<field> -> <collection>.<field>` (e.g. `users.company -> companies._id`).
+	Relations []string
+	// Schemas are JSON Schema file paths for validating documents.
+	//   key: collection name
+	//   value: JSON Schema file path
+	// When collection is not configured, `schemas/<collection name>.json` in FixtureRootDir is used if exists.
+	Schemas map[string]string
+	// CollectionOptions are options for creating collections.
+	//   key: collection name
+	//   value: options (overwritten by `_options` in fixture)
+	CollectionOptions map[string]CollectionOptions
 
 	fixtureRootDirAbs string
+	relations         []relation
 }
 
 var conf = defaultConfig()
@@ -47,6 +134,10 @@ func defaultConfig() Config {
 		FixtureFormat:  FixtureFormatAuto,
 		Timeout:        defaultTimeoutSeconds,
 		PreInsertFuncs: make([]PreInsertFunc, 0),
+		LoadMode:       LoadModeDrop,
+		DocumentOrder:  DocumentOrderFile,
+		CleanMode:      CleanModeNone,
+		Workers:        defaultWorkers,
 	}
 }
 
@@ -55,22 +146,56 @@ type PreInsertFunc func(collectionName string, doc DocData) (DocData, error)
 
 func validateConfig() error {
 	if conf.URL == "" {
-		return errors.New("empty URL")
+		return fmt.Errorf("%w: empty URL", ErrInvalidConfig)
 	}
 	if conf.Database == "" {
-		return errors.New("empty Database name")
+		return fmt.Errorf("%w: empty Database name", ErrInvalidConfig)
 	}
 	if conf.Timeout <= 0 {
-		return errors.New("invalid Timeout seconds")
+		return fmt.Errorf("%w: invalid Timeout seconds", ErrInvalidConfig)
+	}
+	if conf.Workers <= 0 {
+		return fmt.Errorf("%w: invalid Workers", ErrInvalidConfig)
+	}
+	if conf.BatchSize < 0 {
+		return fmt.Errorf("%w: invalid BatchSize", ErrInvalidConfig)
+	}
+	for _, ext := range conf.FixtureExtensions {
+		if !strings.HasPrefix(ext, ".") {
+			return fmt.Errorf("%w: invalid FixtureExtensions %q", ErrInvalidConfig, ext)
+		}
+	}
+	if err := validateLoadMode(conf.LoadMode); err != nil {
+		return err
+	}
+	if err := validateCleanMode(conf.CleanMode); err != nil {
+		return err
+	}
+	if err := validateDocumentOrder(conf.DocumentOrder); err != nil {
+		return err
 	}
 	abs, err := filepath.Abs(conf.FixtureRootDir)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, err)
 	}
 	conf.fixtureRootDirAbs = abs
+	rels, err := parseRelations(conf.Relations)
+	if err != nil {
+		return err
+	}
+	conf.relations = rels
 	return nil
 }
 
+func validateLoadMode(mode LoadModeType) error {
+	switch mode {
+	case LoadModeDrop, LoadModeTruncate, LoadModeUpsert, LoadModeAppend:
+		return nil
+	default:
+		return fmt.Errorf("%w: invalid LoadMode %q", ErrInvalidConfig, mode)
+	}
+}
+
 // Configure overwrite configuration by given config.
 func Configure(c Config) {
 	if c.URL != "" {
@@ -85,10 +210,55 @@ func Configure(c Config) {
 	if c.FixtureFormat != fixtureFormatEmpty {
 		conf.FixtureFormat = c.FixtureFormat
 	}
+	if c.FixtureExtensions != nil {
+		conf.FixtureExtensions = c.FixtureExtensions
+	}
 	if c.Timeout > 0 {
 		conf.Timeout = c.Timeout
 	}
 	if c.PreInsertFuncs != nil {
 		conf.PreInsertFuncs = c.PreInsertFuncs
 	}
+	if c.LoadMode != loadModeEmpty {
+		conf.LoadMode = c.LoadMode
+	}
+	if c.DocumentOrder != documentOrderEmpty {
+		conf.DocumentOrder = c.DocumentOrder
+	}
+	if c.CleanMode != cleanModeEmpty {
+		conf.CleanMode = c.CleanMode
+	}
+	if c.CleanExcludes != nil {
+		conf.CleanExcludes = c.CleanExcludes
+	}
+	if c.Transaction {
+		conf.Transaction = c.Transaction
+	}
+	if c.Workers > 0 {
+		conf.Workers = c.Workers
+	}
+	if c.BatchSize > 0 {
+		conf.BatchSize = c.BatchSize
+	}
+	if c.UnorderedInsert {
+		conf.UnorderedInsert = c.UnorderedInsert
+	}
+	if c.Stream {
+		conf.Stream = c.Stream
+	}
+	if c.DisableCache {
+		conf.DisableCache = c.DisableCache
+	}
+	if c.SkipUnchanged {
+		conf.SkipUnchanged = c.SkipUnchanged
+	}
+	if c.Relations != nil {
+		conf.Relations = c.Relations
+	}
+	if c.Schemas != nil {
+		conf.Schemas = c.Schemas
+	}
+	if c.CollectionOptions != nil {
+		conf.CollectionOptions = c.CollectionOptions
+	}
 }
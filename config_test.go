@@ -24,6 +24,15 @@ func TestDefaultConfig(t *testing.T) {
 	if got := conf.FixtureFormat; got != mongotest.FixtureFormatAuto {
 		t.Errorf("default fixture format configuration should be auto but got %q", got)
 	}
+	if got := conf.Workers; got != 1 {
+		t.Errorf("default workers configuration should be 1 but got %d", got)
+	}
+	if got := conf.DocumentOrder; got != mongotest.DocumentOrderFile {
+		t.Errorf("default document order configuration should be file but got %q", got)
+	}
+	if got := conf.LoadMode; got != mongotest.LoadModeDrop {
+		t.Errorf("default load mode configuration should be drop but got %q", got)
+	}
 }
 
 func TestConfigure(t *testing.T) {
@@ -34,6 +43,7 @@ func TestConfigure(t *testing.T) {
 		FixtureRootDir: "testdata",
 		FixtureFormat:  mongotest.FixtureFormatJSON,
 		Timeout:        30,
+		LoadMode:       mongotest.LoadModeTruncate,
 		PreInsertFuncs: []mongotest.PreInsertFunc{
 			mongotest.SimpleConvertTime("users", "created_at"),
 		},
@@ -55,6 +65,9 @@ func TestConfigure(t *testing.T) {
 	if conf.Timeout != c.Timeout {
 		t.Errorf("Timeout should be overwritten. (want: %d, got: %d", c.Timeout, conf.Timeout)
 	}
+	if conf.LoadMode != c.LoadMode {
+		t.Errorf("LoadMode should be overwritten. (want: %q, got: %q", c.LoadMode, conf.LoadMode)
+	}
 	if len(conf.PreInsertFuncs) != len(c.PreInsertFuncs) {
 		t.Errorf("PreInsertFuncs should be overwritten. (want: %#v, got: %#v", c.PreInsertFuncs, conf.PreInsertFuncs)
 	}
@@ -13,7 +13,7 @@ func connect(ctx context.Context) (context.Context, *mongo.Client, context.Cance
 	if err != nil {
 		return ctx, nil, nil, err
 	}
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(conf.Timeout)*time.Second)
+	ctx, cancel := withTimeout(ctx)
 	err = client.Connect(ctx)
 	if err != nil {
 		cancel()
@@ -25,6 +25,11 @@ func connect(ctx context.Context) (context.Context, *mongo.Client, context.Cance
 	}, nil
 }
 
+// withTimeout returns context that is limited by configured timeout.
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, time.Duration(conf.Timeout)*time.Second)
+}
+
 func connectCollection(ctx context.Context, collName string) (context.Context, *mongo.Collection, context.CancelFunc, error) {
 	ctx, client, cancel, err := connect(ctx)
 	if err != nil {
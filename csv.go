@@ -0,0 +1,189 @@
+package mongotest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tkuchiki/parsetime"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// csvKeyField is field name of column that has document key.
+	csvKeyField = "_id"
+	// csvArraySeparator separates elements of array column.
+	csvArraySeparator = "|"
+)
+
+// csvColumn is column of CSV fixture.
+// Column is written as `<field>[:<type>]` in header row. (e.g. `age:int`, `address.city`)
+type csvColumn struct {
+	path []string
+	typ  string
+}
+
+// readCSVDataSet decodes CSV (or TSV) fixture that holds documents of a collection.
+// Header row has field names, and each row is a document.
+// Empty cell means that the field does not exist in the document.
+func readCSVDataSet(bs []byte, comma rune, collName string) (DataSet, dataOrder, error) {
+	r := csv.NewReader(bytes.NewReader(bs))
+	r.Comma = comma
+	header, err := r.Read()
+	if err == io.EOF {
+		return DataSet{collName: CollectionData{}}, dataOrder{}, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	cols, keyIndex, err := toCSVColumns(header)
+	if err != nil {
+		line, col := r.FieldPos(0)
+		return nil, nil, &FixtureError{Line: line, Column: col, Collection: collName, Err: err}
+	}
+	cd := make(CollectionData)
+	order := make(dataOrder)
+	var errs MultiError
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		key := record[keyIndex]
+		if key == "" {
+			line, col := r.FieldPos(keyIndex)
+			errs = append(errs, &FixtureError{Line: line, Column: col, Collection: collName, Err: fmt.Errorf("empty %s", csvKeyField)})
+			continue
+		}
+		if _, ok := cd[key]; ok {
+			line, _ := r.FieldPos(keyIndex)
+			errs = append(errs, &FixtureError{Line: line, Collection: collName, Key: key, Err: ErrDuplicateKey})
+			continue
+		}
+		doc, i, err := toCSVDoc(cols, keyIndex, record)
+		if err != nil {
+			line, col := r.FieldPos(i)
+			errs = append(errs, &FixtureError{Line: line, Column: col, Collection: collName, Key: key, Err: err})
+			continue
+		}
+		cd[key] = doc
+		order[collName] = append(order[collName], key)
+	}
+	if err := errs.err(); err != nil {
+		return nil, nil, err
+	}
+	return DataSet{collName: cd}, order, nil
+}
+
+func toCSVColumns(header []string) ([]csvColumn, int, error) {
+	cols := make([]csvColumn, len(header))
+	keyIndex := -1
+	for i, h := range header {
+		field, typ := h, "string"
+		if n := strings.LastIndex(h, ":"); n >= 0 {
+			field, typ = h[:n], strings.ToLower(h[n+1:])
+		}
+		if !isCSVType(typ) {
+			return nil, 0, fmt.Errorf("unknown type %q of column %s", typ, field)
+		}
+		if field == csvKeyField {
+			if typ != "string" {
+				return nil, 0, fmt.Errorf("type of %s column must be string", csvKeyField)
+			}
+			keyIndex = i
+		}
+		cols[i] = csvColumn{path: strings.Split(field, "."), typ: typ}
+	}
+	if keyIndex < 0 {
+		return nil, 0, fmt.Errorf("no %s column", csvKeyField)
+	}
+	return cols, keyIndex, nil
+}
+
+func isCSVType(typ string) bool {
+	switch typ {
+	case "string", "int", "long", "double", "bool", "date", "array", "objectid":
+		return true
+	default:
+		return false
+	}
+}
+
+// toCSVDoc converts record into document. Index of column is returned with error.
+func toCSVDoc(cols []csvColumn, keyIndex int, record []string) (DocData, int, error) {
+	doc := make(DocData)
+	for i, s := range record {
+		if i == keyIndex || s == "" {
+			continue
+		}
+		col := cols[i]
+		v, err := toCSVValue(col.typ, s)
+		if err != nil {
+			return nil, i, fmt.Errorf("field %s: %w", strings.Join(col.path, "."), err)
+		}
+		if err = setFieldValue(doc, col.path, v); err != nil {
+			return nil, i, err
+		}
+	}
+	return doc, 0, nil
+}
+
+func toCSVValue(typ string, s string) (interface{}, error) {
+	switch typ {
+	case "int":
+		n, err := strconv.ParseInt(s, 10, 32)
+		return int32(n), err
+	case "long":
+		return strconv.ParseInt(s, 10, 64)
+	case "double":
+		return strconv.ParseFloat(s, 64)
+	case "bool":
+		return strconv.ParseBool(s)
+	case "date":
+		p, err := parsetime.NewParseTime()
+		if err != nil {
+			return nil, err
+		}
+		return p.Parse(s)
+	case "array":
+		elems := strings.Split(s, csvArraySeparator)
+		a := make([]interface{}, len(elems))
+		for i, e := range elems {
+			a[i] = e
+		}
+		return a, nil
+	case "objectid":
+		return primitive.ObjectIDFromHex(s)
+	default:
+		return s, nil
+	}
+}
+
+// setFieldValue sets value into nested document with dotted field path.
+func setFieldValue(doc map[string]interface{}, path []string, v interface{}) error {
+	m := doc
+	for i, name := range path[:len(path)-1] {
+		child, ok := m[name]
+		if !ok {
+			nm := make(map[string]interface{})
+			m[name] = nm
+			m = nm
+			continue
+		}
+		if m, ok = child.(map[string]interface{}); !ok {
+			return fmt.Errorf("field %s is not a document", strings.Join(path[:i+1], "."))
+		}
+	}
+	last := path[len(path)-1]
+	if _, ok := m[last]; ok {
+		return fmt.Errorf("field %s is duplicated", strings.Join(path, "."))
+	}
+	m[last] = v
+	return nil
+}
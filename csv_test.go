@@ -0,0 +1,72 @@
+package mongotest_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestUseFixtureCSVFormat(t *testing.T) {
+	err := mongotest.UseFixture("csv/products", "csv/companies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := mongotest.Find("products", "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"_id":         "p1",
+		"name":        "apple",
+		"price":       1.5,
+		"stock":       int32(10),
+		"tags":        primitive.A{"fruit", "red"},
+		"maker":       primitive.M{"name": "foo", "country": "JP"},
+		"released_at": createdAtPrimitive,
+	}
+	if !reflect.DeepEqual(saved, want) {
+		t.Errorf("saved product is invalid. (%v)", diffMap(saved, want))
+	}
+	saved, err = mongotest.Find("products", "p2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := saved["stock"]; ok {
+		t.Errorf("field of empty cell should not be saved (got: %v)", saved["stock"])
+	}
+
+	saved, err = mongotest.Find("companies", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]interface{}{
+		"_id":    "bar",
+		"name":   "bar company",
+		"active": false,
+	}
+	if !reflect.DeepEqual(saved, want) {
+		t.Errorf("saved company is invalid. (%v)", diffMap(saved, want))
+	}
+}
+
+func TestUseFixtureCSVFormatWithInvalidValue(t *testing.T) {
+	err := mongotest.UseFixture("csv/invalid_products")
+	var fe *mongotest.FixtureError
+	if !errors.As(err, &fe) {
+		t.Fatalf("should return FixtureError but got %v", err)
+	}
+	if got := filepath.Base(fe.File); got != "invalid_products.csv" {
+		t.Errorf("file of error is invalid (want: %s, got: %s)", "invalid_products.csv", got)
+	}
+	if fe.Line != 2 || fe.Column != 10 {
+		t.Errorf("position of error is invalid (want: 2:10, got: %d:%d)", fe.Line, fe.Column)
+	}
+	if fe.Collection != "invalid_products" || fe.Key != "p1" {
+		t.Errorf("location of error is invalid (want: invalid_products.p1, got: %s.%s)", fe.Collection, fe.Key)
+	}
+}
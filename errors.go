@@ -0,0 +1,221 @@
+package mongotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidConfig is returned when configuration or load options are invalid.
+	ErrInvalidConfig = errors.New("invalid config")
+	// ErrFixtureNotFound is returned when fixture file is not found by given name.
+	ErrFixtureNotFound = errors.New("fixture not found")
+	// ErrAmbiguousFixture is returned when fixture name matches multiple files.
+	ErrAmbiguousFixture = errors.New("ambiguous fixture")
+	// ErrUnknownFormat is returned when format of fixture file cannot be decided.
+	ErrUnknownFormat = errors.New("unknown format")
+	// ErrDuplicateKey is returned when document key is duplicated in a fixture file,
+	// or when inserting document violates unique constraint.
+	ErrDuplicateKey = errors.New("duplicate key")
+)
+
+// sentinelError is error that matches sentinel error with keeping the underlying error.
+type sentinelError struct {
+	sentinel error
+	err      error
+}
+
+func (e *sentinelError) Error() string {
+	return e.err.Error()
+}
+
+func (e *sentinelError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+func (e *sentinelError) Unwrap() error {
+	return e.err
+}
+
+// FixtureError is error of loading fixture with location where the error occurred.
+// Fields are empty (or zero) when the location is unknown.
+type FixtureError struct {
+	// File is path of fixture file.
+	File string
+	// Line is line number in fixture file. (1-based)
+	Line int
+	// Column is column number in fixture file. (1-based)
+	Column int
+	// Collection is name of collection.
+	Collection string
+	// Key is document key in fixture.
+	Key string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *FixtureError) Error() string {
+	var locs []string
+	if e.File != "" {
+		file := e.File
+		if e.Line > 0 {
+			file += ":" + strconv.Itoa(e.Line)
+			if e.Column > 0 {
+				file += ":" + strconv.Itoa(e.Column)
+			}
+		}
+		locs = append(locs, "file: "+file)
+	}
+	if e.Collection != "" {
+		locs = append(locs, "collection: "+e.Collection)
+	}
+	if e.Key != "" {
+		locs = append(locs, "document: "+e.Key)
+	}
+	if len(locs) == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s (%s)", e.Err, strings.Join(locs, ", "))
+}
+
+// Unwrap returns the underlying error.
+func (e *FixtureError) Unwrap() error {
+	return e.Err
+}
+
+// withLocation fills empty location of FixtureErrors in err, or wraps err with FixtureError.
+func withLocation(err error, file, collName, key string) error {
+	if err == nil {
+		return nil
+	}
+	switch te := err.(type) {
+	case *FixtureError:
+		if te.File == "" {
+			te.File = file
+		}
+		if te.Collection == "" {
+			te.Collection = collName
+		}
+		if te.Key == "" {
+			te.Key = key
+		}
+		return te
+	case MultiError:
+		for i, e := range te {
+			te[i] = withLocation(e, file, collName, key)
+		}
+		return te
+	default:
+		return &FixtureError{File: file, Collection: collName, Key: key, Err: err}
+	}
+}
+
+func isFixtureError(err error) bool {
+	var fe *FixtureError
+	return errors.As(err, &fe)
+}
+
+// isDuplicateKeyCode returns true if given server error code means duplicate key error.
+func isDuplicateKeyCode(code int) bool {
+	return code == 11000 || code == 11001 || code == 12582
+}
+
+// lineColumn returns 1-based line and column of given byte offset.
+func lineColumn(bs []byte, offset int64) (int, int) {
+	if offset < 0 || offset > int64(len(bs)) {
+		return 0, 0
+	}
+	before := bs[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(offset) - (bytes.LastIndexByte(before, '\n') + 1) + 1
+	return line, col
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// yamlErrorLine returns line number written in error message of YAML decoder.
+func yamlErrorLine(err error) int {
+	m := yamlLinePattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// jsonErrorOffset returns byte offset where JSON decoding error occurred.
+// Offsets reported by decoder point after the invalid byte, so the previous offset is returned.
+func jsonErrorOffset(err error, r *jsonDocReader) int64 {
+	offset := r.dec.InputOffset()
+	var se *json.SyntaxError
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &se) {
+		offset = se.Offset
+	} else if errors.As(err, &te) {
+		offset = te.Offset
+	}
+	if offset > 0 {
+		offset--
+	}
+	return offset
+}
+
+// MultiError is error that holds multiple errors.
+// All problems of fixtures are reported at once with MultiError.
+// errors.Is and errors.As match when any of errors matches.
+type MultiError []error
+
+func (m MultiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports whether any error in list matches target. (used by errors.Is)
+func (m MultiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds first error in list that matches target. (used by errors.As)
+func (m MultiError) As(target interface{}) bool {
+	for _, err := range m {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// add appends given error to list. Nested MultiError is flattened and nil is ignored.
+func (m MultiError) add(err error) MultiError {
+	if err == nil {
+		return m
+	}
+	if me, ok := err.(MultiError); ok {
+		for _, e := range me {
+			m = m.add(e)
+		}
+		return m
+	}
+	return append(m, err)
+}
+
+// err returns nil if list is empty.
+func (m MultiError) err() error {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
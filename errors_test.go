@@ -0,0 +1,119 @@
+package mongotest_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestFixtureErrorOfBrokenFile(t *testing.T) {
+	testdata := []struct {
+		name   string
+		file   string
+		line   int
+		column int
+	}{
+		{name: "errors/broken_yaml", file: "broken_yaml.yml", line: 3},
+		{name: "errors/broken_json", file: "broken_json.json", line: 3, column: 31},
+	}
+	for _, d := range testdata {
+		t.Run(d.name, func(t *testing.T) {
+			err := mongotest.UseFixture(d.name)
+			var fe *mongotest.FixtureError
+			if !errors.As(err, &fe) {
+				t.Fatalf("should return FixtureError but got %v", err)
+			}
+			if got := filepath.Base(fe.File); got != d.file {
+				t.Errorf("file of error is invalid (want: %s, got: %s)", d.file, got)
+			}
+			if fe.Line != d.line {
+				t.Errorf("line of error is invalid (want: %d, got: %d)", d.line, fe.Line)
+			}
+			if fe.Column != d.column {
+				t.Errorf("column of error is invalid (want: %d, got: %d)", d.column, fe.Column)
+			}
+		})
+	}
+}
+
+func TestFixtureErrorOfPreInsertFunc(t *testing.T) {
+	err := mongotest.UseFixture("errors/invalid_time")
+	var fe *mongotest.FixtureError
+	if !errors.As(err, &fe) {
+		t.Fatalf("should return FixtureError but got %v", err)
+	}
+	if got := filepath.Base(fe.File); got != "invalid_time.yml" {
+		t.Errorf("file of error is invalid (want: %s, got: %s)", "invalid_time.yml", got)
+	}
+	if fe.Collection != "users" || fe.Key != "user1" {
+		t.Errorf("location of error is invalid (want: users.user1, got: %s.%s)", fe.Collection, fe.Key)
+	}
+}
+
+func TestFixtureErrorOfInsert(t *testing.T) {
+	err := mongotest.UseFixture("modes/more_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := mongotest.LoadOptions{LoadMode: mongotest.LoadModeAppend}
+	err = mongotest.UseFixtureWithOptions(context.Background(), opts, "modes/more_users")
+	var fe *mongotest.FixtureError
+	if !errors.As(err, &fe) {
+		t.Fatalf("should return FixtureError but got %v", err)
+	}
+	if fe.Collection != "users" || fe.Key != "user9" {
+		t.Errorf("location of error is invalid (want: users.user9, got: %s.%s)", fe.Collection, fe.Key)
+	}
+}
+
+func TestErrInvalidConfig(t *testing.T) {
+	reset := mongotest.DefaultConfig()
+	defer reset()
+	err := mongotest.UseFixture("json/admin_users")
+	if !errors.Is(err, mongotest.ErrInvalidConfig) {
+		t.Errorf("should return ErrInvalidConfig but got %v", err)
+	}
+}
+
+func TestErrFixtureNotFound(t *testing.T) {
+	err := mongotest.UseFixture("errors/not_exist1", "errors/not_exist2")
+	if !errors.Is(err, mongotest.ErrFixtureNotFound) {
+		t.Fatalf("should return ErrFixtureNotFound but got %v", err)
+	}
+	var me mongotest.MultiError
+	if !errors.As(err, &me) {
+		t.Fatalf("should return MultiError but got %v", err)
+	}
+	if len(me) != 2 {
+		t.Errorf("all missing fixtures should be reported (want: 2, got: %d)", len(me))
+	}
+}
+
+func TestErrDuplicateKeyInFixture(t *testing.T) {
+	err := mongotest.UseFixture("errors/duplicated_keys")
+	if !errors.Is(err, mongotest.ErrDuplicateKey) {
+		t.Fatalf("should return ErrDuplicateKey but got %v", err)
+	}
+	var me mongotest.MultiError
+	if !errors.As(err, &me) {
+		t.Fatalf("should return MultiError but got %v", err)
+	}
+	if len(me) != 2 {
+		t.Errorf("all duplicated keys should be reported (want: 2, got: %d)", len(me))
+	}
+}
+
+func TestErrDuplicateKeyOfInsert(t *testing.T) {
+	err := mongotest.UseFixture("modes/more_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := mongotest.LoadOptions{LoadMode: mongotest.LoadModeAppend}
+	err = mongotest.UseFixtureWithOptions(context.Background(), opts, "modes/more_users")
+	if !errors.Is(err, mongotest.ErrDuplicateKey) {
+		t.Errorf("should return ErrDuplicateKey but got %v", err)
+	}
+}
@@ -17,3 +17,9 @@ func DefaultConfig() (reset func()) {
 		conf = orig
 	}
 }
+
+func FixtureCacheLen() int {
+	fixtureCache.Lock()
+	defer fixtureCache.Unlock()
+	return len(fixtureCache.entries)
+}
@@ -1,74 +1,263 @@
 package mongotest
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/BurntSushi/toml"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"gopkg.in/yaml.v2"
 )
 
-// UseFixtureWithContext apply fixture data to MongoDB with context.Context.
+// LoadOptions is options for loading fixture that overwrites configuration per call.
+type LoadOptions struct {
+	// LoadMode is mode of loading fixture. If empty, configured LoadMode is used.
+	LoadMode LoadModeType
+	// CleanMode is mode of cleaning collections not contained in fixture. If empty, configured CleanMode is used.
+	CleanMode CleanModeType
+	// PreInsertFuncs are applied to documents after configured PreInsertFuncs.
+	PreInsertFuncs []PreInsertFunc
+}
+
+// withDefaults returns options that empty values are filled with configuration.
+func (o LoadOptions) withDefaults() (LoadOptions, error) {
+	if o.LoadMode == loadModeEmpty {
+		o.LoadMode = conf.LoadMode
+	} else if err := validateLoadMode(o.LoadMode); err != nil {
+		return o, err
+	}
+	if o.CleanMode == cleanModeEmpty {
+		o.CleanMode = conf.CleanMode
+	} else if err := validateCleanMode(o.CleanMode); err != nil {
+		return o, err
+	}
+	return o, nil
+}
+
+// UseFixtureWithOptions apply fixture data to MongoDB with context.Context and LoadOptions.
 // If multi names are given, fixture data will be merged.(overwriting by after dataset)
-func UseFixtureWithContext(ctx context.Context, names ...string) error {
+// Name that starts with `@` means fixture set defined in `fixtures.yml` of FixtureRootDir.
+//
+// Name of directory is loaded in different way by trailing `/`.
+//   - `scenario/checkout/` loads all fixture files in the directory, and each file holds DataSet.
+//   - `scenario/checkout` loads the directory as one-file-per-collection layout only when no fixture file has the name,
+//     and each file holds CollectionData of the collection named by the file. (e.g. `users.yml` for users)
+func UseFixtureWithOptions(ctx context.Context, opts LoadOptions, names ...string) error {
 	if err := validateConfig(); err != nil {
 		return err
 	}
+	names, opts, err := expandFixtureSets(names, opts)
+	if err != nil {
+		return err
+	}
+	opts, err = opts.withDefaults()
+	if err != nil {
+		return err
+	}
 	files, err := toFilePaths(names...)
 	if err != nil {
 		return err
 	}
-	ds, err := loadDataSet(files...)
+	if conf.Stream {
+		return streamFixtures(ctx, opts, files...)
+	}
+	ds, srcs, order, err := loadDataSet(files...)
 	if err != nil {
 		return err
 	}
-	for cn, cd := range ds {
-		vs, err := toValues(cn, cd)
-		if err != nil {
-			return err
-		}
-		err = resetCollection(ctx, cn, vs)
-		if err != nil {
-			return err
-		}
+	optsMap, err := extractCollectionOptions(ds, srcs)
+	if err != nil {
+		return err
 	}
-	return nil
+	var errs MultiError
+	collNames := make([]string, 0, len(ds))
+	for cn := range ds {
+		collNames = append(collNames, cn)
+	}
+	sort.Strings(collNames)
+	docsMap := make(map[string][]fixtureDoc, len(ds))
+	for _, cn := range collNames {
+		cd := ds[cn]
+		keys := orderedKeys(cd, order[cn], optsMap[cn].documentOrder())
+		docs, err := toDocs(cn, cd, keys, srcs[cn], opts.PreInsertFuncs)
+		errs = errs.add(err)
+		docsMap[cn] = docs
+	}
+	if err = errs.err(); err != nil {
+		return err
+	}
+	errs = errs.add(resolveReferences(docsMap))
+	errs = errs.add(validateRelations(docsMap))
+	errs = errs.add(validateSchemas(docsMap))
+	if err = errs.err(); err != nil {
+		return err
+	}
+	loaded := make(map[string]bool, len(ds))
+	for cn := range ds {
+		loaded[cn] = true
+	}
+	markFixtureCollections(loaded)
+	if err = loadFixtureCollections(ctx, opts.LoadMode, optsMap, docsMap); err != nil {
+		return err
+	}
+	// Other collections are cleaned after loading, so they are kept when loading is failed.
+	return cleanCollections(ctx, opts.CleanMode, loaded)
+}
+
+// loadFixtureCollections loads collections with skipping unchanged collections if SkipUnchanged is configured.
+func loadFixtureCollections(ctx context.Context, mode LoadModeType, optsMap map[string]CollectionOptions, docsMap map[string][]fixtureDoc) error {
+	if !conf.SkipUnchanged {
+		return loadCollections(ctx, mode, optsMap, docsMap)
+	}
+	hashes, err := skipUnchangedCollections(ctx, mode, optsMap, docsMap)
+	if err != nil {
+		return err
+	}
+	err = loadCollections(ctx, mode, optsMap, docsMap)
+	return recordLoadedCollections(ctx, hashes, err)
+}
+
+// UseFixtureWithContext apply fixture data to MongoDB with context.Context.
+// If multi names are given, fixture data will be merged.(overwriting by after dataset)
+func UseFixtureWithContext(ctx context.Context, names ...string) error {
+	return UseFixtureWithOptions(ctx, LoadOptions{}, names...)
 }
 
 // UseFixture apply fixture data to MongoDB.
 // If multi names are given, fixture data will be merged.(overwriting by after dataset)
+// See UseFixtureWithOptions for names of directory.
 func UseFixture(names ...string) error {
 	return UseFixtureWithContext(context.Background(), names...)
 }
 
-func toFilePaths(names ...string) ([]string, error) {
-	files := make([]string, len(names))
-	for i, name := range names {
-		file, err := findFixtureFilePath(name)
+// fixtureFile is fixture file to be loaded.
+type fixtureFile struct {
+	path string
+	// collection is name of collection that file holds in one-file-per-collection layout.
+	// File holds DataSet when collection is empty.
+	collection string
+	// layout is names of collections in the directory of one-file-per-collection layout.
+	layout map[string]bool
+}
+
+// collectionName returns name of collection that file holds when file is collection file.
+// Name of file without extension is used when not in one-file-per-collection layout.
+func (f fixtureFile) collectionName() string {
+	if f.collection != "" {
+		return f.collection
+	}
+	name := filepath.Base(f.path)
+	return strings.TrimSuffix(name, fixtureExt(name))
+}
+
+func toFilePaths(names ...string) ([]fixtureFile, error) {
+	files := make([]fixtureFile, 0, len(names))
+	var errs MultiError
+	for _, name := range names {
+		fs, err := findFixtureFiles(name)
+		errs = errs.add(err)
+		files = append(files, fs...)
+	}
+	return files, errs.err()
+}
+
+func findFixtureFiles(name string) ([]fixtureFile, error) {
+	if isFixturePattern(name) {
+		paths, err := findFixtureFilePaths(name)
 		if err != nil {
 			return nil, err
 		}
-		files[i] = file
+		files := make([]fixtureFile, len(paths))
+		for i, p := range paths {
+			files[i] = fixtureFile{path: p}
+		}
+		return files, nil
+	}
+	file, err := findFixtureFilePath(name)
+	if errors.Is(err, ErrFixtureNotFound) {
+		// Directory is used only when no file matches the name.
+		if dir := filepath.Join(fixturePath(name)); isDir(dir) {
+			return findCollectionFiles(name, dir)
+		}
 	}
-	return files, nil
+	if err != nil {
+		return nil, err
+	}
+	return []fixtureFile{{path: file}}, nil
 }
 
 func findFixtureFilePath(name string) (string, error) {
 	dir, base := fixturePath(name)
 	fis, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%w: DataSet %q (directory %s does not exist)", ErrFixtureNotFound, name, dir)
+	}
 	if err != nil {
 		return "", err
 	}
+	var candidates []string
 	for _, fi := range fis {
-		if fi.Name() == base+filepath.Ext(fi.Name()) && !fi.IsDir() {
+		if fi.IsDir() {
+			continue
+		}
+		// Name that includes extension selects the file exactly.
+		if fi.Name() == base {
 			return filepath.Join(dir, fi.Name()), nil
 		}
+		if strings.TrimSuffix(fi.Name(), fixtureExt(fi.Name())) == base && isFixtureFile(fi.Name()) {
+			candidates = append(candidates, fi.Name())
+		}
 	}
-	return "", fmt.Errorf("DataSet %q not found in %s", name, conf.fixtureRootDirAbs)
+	file, err := selectFixtureFile(candidates)
+	if errors.Is(err, ErrAmbiguousFixture) {
+		return "", fmt.Errorf("%w: DataSet %q matches %s in %s", err, name, strings.Join(candidates, ", "), dir)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: DataSet %q in %s", err, name, dir)
+	}
+	return filepath.Join(dir, file), nil
+}
+
+// selectFixtureFile selects a file from files that have same base name.
+// When FixtureExtensions is configured, the file that has the highest priority extension is selected.
+func selectFixtureFile(files []string) (string, error) {
+	if len(conf.FixtureExtensions) > 0 {
+		for _, ext := range conf.FixtureExtensions {
+			for _, file := range files {
+				if strings.EqualFold(fixtureExt(file), ext) {
+					return file, nil
+				}
+			}
+		}
+		return "", ErrFixtureNotFound
+	}
+	switch len(files) {
+	case 0:
+		return "", ErrFixtureNotFound
+	case 1:
+		return files[0], nil
+	default:
+		return "", ErrAmbiguousFixture
+	}
+}
+
+// fixtureExt returns extension of fixture file.
+// Extension of compressed file includes compression suffix. (e.g. `.json.gz`)
+func fixtureExt(file string) string {
+	name, cext := splitCompressionExt(file)
+	return filepath.Ext(name) + cext
 }
 
 func fixturePath(name string) (dir string, base string) {
@@ -88,59 +277,264 @@ func splitDataSetName(name string) []string {
 	return []string{name}
 }
 
-func loadDataSet(files ...string) (DataSet, error) {
-	dss, err := toDataSets(files...)
+// dataSources holds fixture file path of each document.
+//
+//	key: collection name
+//	value: map of document key and file path (last file when document is merged)
+type dataSources map[string]map[string]string
+
+func loadDataSet(files ...fixtureFile) (DataSet, dataSources, dataOrder, error) {
+	dss, orders, err := toDataSets(files...)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
+	}
+	return mergeDataSet(dss), toDataSources(files, dss), mergeDataOrder(orders), nil
+}
+
+func toDataSources(files []fixtureFile, dss []DataSet) dataSources {
+	srcs := make(dataSources)
+	for i, ds := range dss {
+		for cn, cd := range ds {
+			if _, ok := srcs[cn]; !ok {
+				srcs[cn] = make(map[string]string, len(cd))
+			}
+			for key := range cd {
+				srcs[cn][key] = files[i].path
+			}
+		}
 	}
-	return mergeDataSet(dss), nil
+	return srcs
 }
 
-func toDataSets(files ...string) ([]DataSet, error) {
+func toDataSets(files ...fixtureFile) ([]DataSet, []dataOrder, error) {
 	dss := make([]DataSet, len(files))
+	orders := make([]dataOrder, len(files))
+	var errs MultiError
 	for i, file := range files {
-		ds, err := readFixtureFile(file)
-		if err != nil {
-			return nil, err
-		}
+		ds, order, err := loadFixtureFile(file)
+		errs = errs.add(err)
 		dss[i] = ds
+		orders[i] = order
+	}
+	if err := errs.err(); err != nil {
+		return nil, nil, err
 	}
-	return dss, nil
+	return dss, orders, nil
 }
 
-func readFixtureFile(file string) (DataSet, error) {
-	format, err := fixtureFormat(file)
+func readFixtureFile(f fixtureFile) (DataSet, dataOrder, error) {
+	format, err := fixtureFormat(f.path)
 	if err != nil {
-		return nil, err
+		return nil, nil, &FixtureError{File: f.path, Err: err}
 	}
-	bs, err := ioutil.ReadFile(file)
+	bs, err := readFixtureBytes(f.path)
 	if err != nil {
-		return nil, err
+		return nil, nil, &FixtureError{File: f.path, Err: err}
 	}
-	ds := make(DataSet)
-	if format == FixtureFormatYAML {
-		err = yaml.Unmarshal(bs, &ds)
-	} else if format == FixtureFormatJSON {
-		err = json.Unmarshal(bs, &ds)
+	var (
+		ds    DataSet
+		order dataOrder
+	)
+	switch format {
+	case FixtureFormatYAML:
+		ds, order, err = readYAMLDataSet(bs, f.collection)
+		if err != nil && !isFixtureError(err) {
+			err = &FixtureError{Line: yamlErrorLine(err), Err: err}
+		}
+	case FixtureFormatJSON:
+		r := newJSONDocReader(ioutil.NopCloser(bytes.NewReader(bs)), f.collection)
+		ds, order, err = readDataSet(r)
+		if err != nil && !isFixtureError(err) {
+			line, col := lineColumn(bs, jsonErrorOffset(err, r))
+			err = &FixtureError{Line: line, Column: col, Err: err}
+		}
+	case FixtureFormatTOML:
+		ds, order, err = readTOMLDataSet(bs, f.collection)
+		var pe toml.ParseError
+		if errors.As(err, &pe) {
+			line, col := lineColumn(bs, int64(pe.Position.Start))
+			err = &FixtureError{Line: line, Column: col, Err: err}
+		}
+	case FixtureFormatCSV, FixtureFormatTSV:
+		comma := ','
+		if format == FixtureFormatTSV {
+			comma = '\t'
+		}
+		ds, order, err = readCSVDataSet(bs, comma, f.collectionName())
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			err = &FixtureError{Line: pe.Line, Column: pe.Column, Err: err}
+		}
+	case FixtureFormatNDJSON:
+		ds, order, err = readDataSet(newNDJSONDocReader(ioutil.NopCloser(bytes.NewReader(bs)), f.collectionName()))
+	case FixtureFormatBSON:
+		var entries []docEntry
+		entries, err = readBSONMetadata(bsonMetadataPath(f.path), f.collectionName())
+		if err == nil {
+			ds, order, err = readDataSet(newBSONDocReader(ioutil.NopCloser(bytes.NewReader(bs)), f.collectionName(), entries))
+		}
+	default:
+		err = ErrUnknownFormat
 	}
 	if err != nil {
-		return nil, err
+		return nil, nil, withLocation(err, f.path, f.collection, "")
+	}
+	if f.collection != "" {
+		var errs MultiError
+		for _, key := range order[f.collection] {
+			errs = errs.add(checkCollectionKey(f, key, ds[f.collection][key]))
+		}
+		if err = errs.err(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return ds, order, nil
+}
+
+// readYAMLDataSet decodes YAML fixture with keeping order of documents.
+// When collName is not empty, fixture is decoded as CollectionData of the collection.
+func readYAMLDataSet(bs []byte, collName string) (DataSet, dataOrder, error) {
+	var ms yaml.MapSlice
+	if err := yaml.Unmarshal(bs, &ms); err != nil {
+		return nil, nil, err
+	}
+	if collName != "" {
+		var docs interface{}
+		if ms != nil {
+			docs = ms
+		}
+		ms = yaml.MapSlice{{Key: collName, Value: docs}}
+	}
+	ds := make(DataSet, len(ms))
+	order := make(dataOrder, len(ms))
+	var errs MultiError
+	for _, ci := range ms {
+		cn := fmt.Sprint(ci.Key)
+		docs, ok := ci.Value.(yaml.MapSlice)
+		if !ok && ci.Value != nil {
+			return nil, nil, &FixtureError{Collection: cn, Err: errors.New("invalid collection data")}
+		}
+		cd, ok := ds[cn]
+		if !ok {
+			cd = make(CollectionData, len(docs))
+			ds[cn] = cd
+		}
+		for _, di := range docs {
+			key := fmt.Sprint(di.Key)
+			doc, ok := normalizeValue(di.Value).(map[string]interface{})
+			if !ok && di.Value != nil {
+				return nil, nil, &FixtureError{Collection: cn, Key: key, Err: errors.New("invalid document data")}
+			}
+			if _, ok := cd[key]; ok {
+				errs = append(errs, &FixtureError{Collection: cn, Key: key, Err: ErrDuplicateKey})
+				continue
+			}
+			order[cn] = append(order[cn], key)
+			cd[key] = doc
+		}
+	}
+	if err := errs.err(); err != nil {
+		return nil, nil, err
+	}
+	return ds, order, nil
+}
+
+// readDataSet reads all documents from docReader into DataSet with keeping order of documents.
+func readDataSet(r docReader) (DataSet, dataOrder, error) {
+	defer r.Close()
+	ds := make(DataSet)
+	order := make(dataOrder)
+	var errs MultiError
+	for {
+		cn, key, doc, err := r.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		cd, ok := ds[cn]
+		if !ok {
+			cd = make(CollectionData)
+			ds[cn] = cd
+		}
+		if _, ok := cd[key]; ok {
+			errs = append(errs, &FixtureError{Collection: cn, Key: key, Err: ErrDuplicateKey})
+			continue
+		}
+		order[cn] = append(order[cn], key)
+		cd[key] = doc
+	}
+	for _, cn := range r.collections() {
+		if _, ok := ds[cn]; !ok {
+			ds[cn] = make(CollectionData)
+		}
+	}
+	if err := errs.err(); err != nil {
+		return nil, nil, err
+	}
+	return ds, order, nil
+}
+
+// normalizeValue converts nested maps decoded from YAML (yaml.MapSlice and map[interface{}]interface{})
+// into map[string]interface{} so that all formats are handled in the same way.
+func normalizeValue(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case yaml.MapSlice:
+		m := make(map[string]interface{}, len(tv))
+		for _, item := range tv {
+			m[fmt.Sprint(item.Key)] = normalizeValue(item.Value)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(tv))
+		for k, v := range tv {
+			m[fmt.Sprint(k)] = normalizeValue(v)
+		}
+		return m
+	case map[string]interface{}:
+		for k, v := range tv {
+			tv[k] = normalizeValue(v)
+		}
+		return tv
+	case []interface{}:
+		for i, v := range tv {
+			tv[i] = normalizeValue(v)
+		}
+		return tv
+	case primitive.M:
+		return normalizeValue(map[string]interface{}(tv))
+	case primitive.A:
+		return normalizeValue([]interface{}(tv))
+	default:
+		return v
 	}
-	return ds, nil
 }
 
 func fixtureFormat(file string) (FixtureFormatType, error) {
 	if conf.FixtureFormat != FixtureFormatAuto {
 		return conf.FixtureFormat, nil
 	}
-	ext := strings.ToLower(filepath.Ext(file))
+	// Compressed file is decided with extension before compression suffix.
+	name, _ := splitCompressionExt(file)
+	ext := strings.ToLower(filepath.Ext(name))
 	switch ext {
 	case ".json":
 		return FixtureFormatJSON, nil
 	case ".yaml", ".yml":
 		return FixtureFormatYAML, nil
+	case ".toml":
+		return FixtureFormatTOML, nil
+	case ".csv":
+		return FixtureFormatCSV, nil
+	case ".tsv":
+		return FixtureFormatTSV, nil
+	case ".ndjson", ".jsonl":
+		return FixtureFormatNDJSON, nil
+	case ".bson":
+		return FixtureFormatBSON, nil
 	default:
-		return fixtureFormatUnknown, errors.New("unknown format")
+		return fixtureFormatUnknown, ErrUnknownFormat
 	}
 }
 
@@ -184,30 +578,71 @@ func mergeDocData(doc1, doc2 DocData) DocData {
 	return merged
 }
 
-func toValues(collectionName string, coll CollectionData) ([]interface{}, error) {
-	values := make([]interface{}, 0, len(coll))
-	for id, doc := range coll {
-		newDoc := make(DocData)
-		for k, v := range doc {
-			newDoc[k] = v
-		}
-		newDoc["_id"] = id
-		v, err := applyPreFuncs(collectionName, newDoc)
+// fixtureDoc is document data that is ready for inserting with its key in fixture.
+type fixtureDoc struct {
+	key  string
+	file string
+	data DocData
+}
+
+// sortedCollectionNames returns names of collections in lexical order.
+func sortedCollectionNames(docsMap map[string][]fixtureDoc) []string {
+	names := make([]string, 0, len(docsMap))
+	for cn := range docsMap {
+		names = append(names, cn)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func toDocs(collectionName string, coll CollectionData, keys []string, srcs map[string]string, funcs []PreInsertFunc) ([]fixtureDoc, error) {
+	docs := make([]fixtureDoc, 0, len(keys))
+	var errs MultiError
+	for _, id := range keys {
+		v, err := toDocData(collectionName, id, coll[id], funcs)
 		if err != nil {
-			return nil, err
+			errs = errs.add(withLocation(err, srcs[id], collectionName, id))
+			continue
 		}
-		values = append(values, v)
+		docs = append(docs, fixtureDoc{key: id, file: srcs[id], data: v})
+	}
+	return docs, errs.err()
+}
+
+// toDocData returns copy of document that has key as _id and is applied configured PreInsertFuncs and given funcs.
+// _id of exported document is kept instead of key.
+func toDocData(collectionName string, key string, doc DocData, funcs []PreInsertFunc) (DocData, error) {
+	newDoc := make(DocData)
+	for k, v := range doc {
+		newDoc[k] = v
+	}
+	if eid, ok := newDoc["_id"].(exportedID); ok {
+		newDoc["_id"] = eid.value
+	} else {
+		newDoc["_id"] = key
+	}
+	v, err := applyPreFuncs(collectionName, newDoc, conf.PreInsertFuncs)
+	if err != nil {
+		return nil, err
 	}
-	return values, nil
+	return applyPreFuncs(collectionName, v, funcs)
 }
 
-func applyPreFuncs(collName string, value DocData) (DocData, error) {
-	if conf.PreInsertFuncs == nil {
+func toValues(docs []fixtureDoc) []interface{} {
+	values := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		values[i] = doc.data
+	}
+	return values
+}
+
+func applyPreFuncs(collName string, value DocData, funcs []PreInsertFunc) (DocData, error) {
+	if funcs == nil {
 		return value, nil
 	}
 	v := value
 	var err error
-	for _, fn := range conf.PreInsertFuncs {
+	for _, fn := range funcs {
 		v, err = fn(collName, v)
 		if err != nil {
 			return nil, err
@@ -216,16 +651,131 @@ func applyPreFuncs(collName string, value DocData) (DocData, error) {
 	return v, nil
 }
 
-func resetCollection(ctx context.Context, name string, values []interface{}) error {
+func resetCollection(ctx context.Context, name string, mode LoadModeType, opts CollectionOptions, docs []fixtureDoc) error {
 	ctx, collection, cancel, err := connectCollection(ctx, name)
 	if err != nil {
 		return err
 	}
 	defer cancel()
-	err = collection.Drop(ctx)
+	err = prepareCollection(ctx, collection, mode, opts)
+	if err != nil {
+		return withLocation(err, "", name, "")
+	}
+	err = writeDocs(ctx, collection, mode, docs)
+	if err != nil {
+		return err
+	}
+	return withLocation(createIndexes(ctx, collection, opts), "", name, "")
+}
+
+func prepareCollection(ctx context.Context, collection *mongo.Collection, mode LoadModeType, opts CollectionOptions) error {
+	switch mode {
+	case LoadModeDrop:
+		if err := collection.Drop(ctx); err != nil {
+			return err
+		}
+		return createCollection(ctx, collection, opts)
+	case LoadModeTruncate:
+		if err := ensureCollection(ctx, collection, opts); err != nil {
+			return err
+		}
+		_, err := collection.DeleteMany(ctx, bson.M{})
+		return err
+	default:
+		return ensureCollection(ctx, collection, opts)
+	}
+}
+
+// ensureCollection creates collection with options only when collection does not exist.
+func ensureCollection(ctx context.Context, collection *mongo.Collection, opts CollectionOptions) error {
+	names, err := collection.Database().ListCollectionNames(ctx, bson.M{"name": collection.Name()})
 	if err != nil {
 		return err
 	}
-	_, err = collection.InsertMany(ctx, values)
+	if len(names) > 0 {
+		return nil
+	}
+	return collection.Database().CreateCollection(ctx, collection.Name(), opts.createOptions())
+}
+
+func createCollection(ctx context.Context, collection *mongo.Collection, opts CollectionOptions) error {
+	if !opts.needsCreate() {
+		return nil
+	}
+	return collection.Database().CreateCollection(ctx, collection.Name(), opts.createOptions())
+}
+
+func createIndexes(ctx context.Context, collection *mongo.Collection, opts CollectionOptions) error {
+	if len(opts.Indexes) == 0 {
+		return nil
+	}
+	_, err := collection.Indexes().CreateMany(ctx, opts.Indexes)
 	return err
 }
+
+// writeDocs writes documents into collection in batches of configured size.
+func writeDocs(ctx context.Context, collection *mongo.Collection, mode LoadModeType, docs []fixtureDoc) error {
+	for _, batch := range batches(docs, conf.BatchSize) {
+		if err := writeBatch(ctx, collection, mode, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeBatch(ctx context.Context, collection *mongo.Collection, mode LoadModeType, docs []fixtureDoc) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	ordered := !conf.UnorderedInsert
+	if mode == LoadModeUpsert {
+		models := make([]mongo.WriteModel, len(docs))
+		for i, doc := range docs {
+			models[i] = mongo.NewReplaceOneModel().
+				SetFilter(bson.M{"_id": doc.data["_id"]}).
+				SetReplacement(doc.data).
+				SetUpsert(true)
+		}
+		_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(ordered))
+		return writeError(err, collection.Name(), docs)
+	}
+	_, err := collection.InsertMany(ctx, toValues(docs), options.InsertMany().SetOrdered(ordered))
+	return writeError(err, collection.Name(), docs)
+}
+
+// writeError converts write errors of bulk operation into FixtureErrors that have location of failed documents.
+func writeError(err error, collName string, docs []fixtureDoc) error {
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) || len(bwe.WriteErrors) == 0 {
+		return withLocation(err, "", collName, "")
+	}
+	errs := make(MultiError, 0, len(bwe.WriteErrors))
+	for _, we := range bwe.WriteErrors {
+		if we.Index < 0 || we.Index >= len(docs) {
+			errs = append(errs, withLocation(we, "", collName, ""))
+			continue
+		}
+		doc := docs[we.Index]
+		var err error = we
+		if isDuplicateKeyCode(we.Code) {
+			err = &sentinelError{sentinel: ErrDuplicateKey, err: we}
+		}
+		errs = append(errs, withLocation(err, doc.file, collName, doc.key))
+	}
+	if bwe.WriteConcernError != nil {
+		errs = append(errs, withLocation(bwe.WriteConcernError, "", collName, ""))
+	}
+	return errs
+}
+
+// batches splits documents by given size. When size is not positive, documents are not split.
+func batches(docs []fixtureDoc, size int) [][]fixtureDoc {
+	if size <= 0 || len(docs) <= size {
+		return [][]fixtureDoc{docs}
+	}
+	bs := make([][]fixtureDoc, 0, (len(docs)+size-1)/size)
+	for size < len(docs) {
+		docs, bs = docs[size:], append(bs, docs[:size])
+	}
+	return append(bs, docs)
+}
@@ -1,6 +1,8 @@
 package mongotest_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -193,3 +195,170 @@ func TestUseFixtureJSONFormat(t *testing.T) {
 		t.Error("should error when invalid fixture format load")
 	}
 }
+
+func TestUseFixtureTOMLFormat(t *testing.T) {
+	err := mongotest.UseFixture("toml/admin_users")
+	if err != nil {
+		t.Error(err)
+	}
+	cnt, err := mongotest.CountInt("users")
+	if err != nil {
+		t.Error(err)
+	}
+	if cnt != 2 {
+		t.Errorf("saved user count is invalid (want: %d, got: %d)", cnt, 2)
+	}
+
+	saved, err := mongotest.Find("users", "admin1")
+	if err != nil {
+		t.Error(err)
+	}
+	want := map[string]interface{}{
+		"_id":        "admin1",
+		"name":       "admin user1",
+		"email":      "admin1@example.com",
+		"admin":      true,
+		"company":    "foo",
+		"age":        int64(30),
+		"note":       "abc",
+		"created_at": createdAtPrimitive,
+	}
+	if !reflect.DeepEqual(saved, want) {
+		t.Errorf("saved user is invalid. (%v)", diffMap(saved, want))
+	}
+	if got := naturalOrderUserIDs(t); !reflect.DeepEqual(got, []interface{}{"admin1", "admin2"}) {
+		t.Errorf("documents should be inserted in order of tables (got: %v)", got)
+	}
+}
+
+func TestUseFixtureWithTruncateMode(t *testing.T) {
+	err := mongotest.UseFixture("indexes/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := mongotest.LoadOptions{LoadMode: mongotest.LoadModeTruncate}
+	err = mongotest.UseFixtureWithOptions(context.Background(), opts, "modes/duplicated_emails")
+	if err == nil {
+		t.Error("should error because unique index is kept in truncate mode")
+	}
+
+	err = mongotest.UseFixture("modes/duplicated_emails")
+	if err != nil {
+		t.Errorf("should not error because unique index is dropped in drop mode (%s)", err)
+	}
+}
+
+func TestUseFixtureWithAppendMode(t *testing.T) {
+	err := mongotest.UseFixture("admin_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := mongotest.LoadOptions{LoadMode: mongotest.LoadModeAppend}
+	err = mongotest.UseFixtureWithOptions(context.Background(), opts, "modes/more_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cnt, err := mongotest.CountInt("users")
+	if err != nil {
+		t.Error(err)
+	}
+	if cnt != 3 {
+		t.Errorf("saved user count is invalid (want: %d, got: %d)", 3, cnt)
+	}
+}
+
+func TestUseFixtureWithUpsertMode(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		LoadMode: mongotest.LoadModeUpsert,
+	})()
+	err := mongotest.UseFixtureWithOptions(context.Background(), mongotest.LoadOptions{LoadMode: mongotest.LoadModeDrop}, "admin_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mongotest.UseFixture("foo_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cnt, err := mongotest.CountInt("users")
+	if err != nil {
+		t.Error(err)
+	}
+	if cnt != 3 {
+		t.Errorf("saved user count is invalid (want: %d, got: %d)", 3, cnt)
+	}
+	saved, err := mongotest.Find("users", "admin1")
+	if err != nil {
+		t.Error(err)
+	}
+	if got := saved["note"]; got != "xyz" {
+		t.Errorf("document should be replaced in upsert mode (want: %q, got: %v)", "xyz", got)
+	}
+}
+
+func TestUseFixtureWithInvalidLoadMode(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		LoadMode: mongotest.LoadModeType("Unknown"),
+	})()
+	err := mongotest.UseFixture("admin_users")
+	if err == nil {
+		t.Error("should error when load mode is invalid")
+	}
+}
+
+func TestUseFixtureWithAmbiguousName(t *testing.T) {
+	err := mongotest.UseFixture("ambiguous/users")
+	if !errors.Is(err, mongotest.ErrAmbiguousFixture) {
+		t.Errorf("should return ErrAmbiguousFixture but got %v", err)
+	}
+}
+
+func TestUseFixtureWithNonFixtureFile(t *testing.T) {
+	if err := mongotest.UseFixture("notes/users"); err != nil {
+		t.Errorf("non fixture file should not make name ambiguous (got: %v)", err)
+	}
+}
+
+func TestUseFixtureWithExtension(t *testing.T) {
+	testdata := []struct {
+		name string
+		exts []string
+		want []interface{}
+	}{
+		{name: "ambiguous/users.yml", want: []interface{}{"yaml_user"}},
+		{name: "ambiguous/users.json", want: []interface{}{"json_user"}},
+		{name: "ambiguous/users", exts: []string{".json", ".yml"}, want: []interface{}{"json_user"}},
+		{name: "ambiguous/users", exts: []string{".yaml", ".yml"}, want: []interface{}{"yaml_user"}},
+	}
+	for _, d := range testdata {
+		t.Run(d.name, func(t *testing.T) {
+			defer mongotest.Reconfigure(mongotest.Config{
+				FixtureExtensions: d.exts,
+			})()
+			if err := mongotest.UseFixture(d.name); err != nil {
+				t.Fatal(err)
+			}
+			if got := naturalOrderUserIDs(t); !reflect.DeepEqual(got, d.want) {
+				t.Errorf("fixture should be selected by extension (want: %v, got: %v)", d.want, got)
+			}
+		})
+	}
+}
+
+func TestUseFixtureWithUnmatchedExtensions(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		FixtureExtensions: []string{".yaml"},
+	})()
+	err := mongotest.UseFixture("ambiguous/users")
+	if !errors.Is(err, mongotest.ErrFixtureNotFound) {
+		t.Errorf("should return ErrFixtureNotFound but got %v", err)
+	}
+}
+
+func TestUseFixtureWithIDField(t *testing.T) {
+	if err := mongotest.UseFixture("ids/users"); err != nil {
+		t.Fatal(err)
+	}
+	if got := naturalOrderUserIDs(t); !reflect.DeepEqual(got, []interface{}{"user1"}) {
+		t.Errorf("key should be used as _id (got: %v)", got)
+	}
+}
@@ -0,0 +1,126 @@
+package mongotest
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// recursiveWildcard matches zero or more directories in fixture name.
+const recursiveWildcard = "**"
+
+// isFixturePattern returns true if given fixture name selects multiple files.
+// Name that ends with `/` selects all fixture files in the directory,
+// and name that contains glob meta characters selects matched fixture files.
+func isFixturePattern(name string) bool {
+	return strings.HasSuffix(name, "/") || hasGlobMeta(name)
+}
+
+func hasGlobMeta(s string) bool {
+	return strings.ContainsAny(s, `*?[\`)
+}
+
+// findFixtureFilePaths returns fixture file paths that matches given directory or glob name.
+// Files are returned in lexical order of walking directories.
+func findFixtureFilePaths(name string) ([]string, error) {
+	pattern := name
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "*"
+	}
+	segs := strings.Split(pattern, "/")
+	i := 0
+	for i < len(segs)-1 && !hasGlobMeta(segs[i]) {
+		i++
+	}
+	dir := filepath.Join(append([]string{conf.fixtureRootDirAbs}, segs[:i]...)...)
+	segs = segs[i:]
+	var files []string
+	err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if p != dir && isExcludedFixtureDir(p, fi.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		ok, err := matchSegments(segs, strings.Split(filepath.ToSlash(rel), "/"))
+		if err != nil {
+			return err
+		}
+		if ok && p != manifestPath() && isFixtureFile(fi.Name()) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: DataSet %q (directory %s does not exist)", ErrFixtureNotFound, name, dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("DataSet %q: %w", name, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: DataSet %q matches no file in %s", ErrFixtureNotFound, name, dir)
+	}
+	return files, nil
+}
+
+// matchSegments reports whether slash separated path segments match pattern segments.
+// `**` segment matches zero or more segments, and other segments are matched with path.Match.
+func matchSegments(pattern, names []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == recursiveWildcard {
+			for i := 0; i <= len(names); i++ {
+				ok, err := matchSegments(pattern[1:], names[i:])
+				if err != nil || ok {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+		if len(names) == 0 {
+			return false, nil
+		}
+		ok, err := path.Match(pattern[0], names[0])
+		if err != nil || !ok {
+			return false, err
+		}
+		pattern, names = pattern[1:], names[1:]
+	}
+	return len(names) == 0, nil
+}
+
+// isExcludedFixtureDir returns true if directory never contains fixture files.
+// Hidden directories and JSON Schema directory are excluded.
+func isExcludedFixtureDir(dir, name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return true
+	}
+	return dir == filepath.Join(conf.fixtureRootDirAbs, schemaDirName)
+}
+
+// isFixtureFile returns true if file is selected by directory or glob name.
+func isFixtureFile(name string) bool {
+	// Metadata file of mongodump is read with BSON file.
+	uncompressed, _ := splitCompressionExt(name)
+	if strings.HasPrefix(name, ".") || strings.HasSuffix(uncompressed, bsonMetadataSuffix) {
+		return false
+	}
+	if len(conf.FixtureExtensions) > 0 {
+		for _, ext := range conf.FixtureExtensions {
+			if strings.EqualFold(fixtureExt(name), ext) {
+				return true
+			}
+		}
+		return false
+	}
+	_, err := fixtureFormat(name)
+	return err == nil
+}
@@ -0,0 +1,44 @@
+package mongotest_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestUseFixtureWithDirectoryAndGlob(t *testing.T) {
+	testdata := []struct {
+		name string
+		want []interface{}
+	}{
+		{name: "scenario/checkout/", want: []interface{}{"user1", "user2", "user3"}},
+		{name: "scenario/checkout/*", want: []interface{}{"user1", "user2", "user3"}},
+		{name: "scenario/checkout/*.yml", want: []interface{}{"user1", "user2"}},
+		{name: "scenario/checkout/**", want: []interface{}{"user1", "user2", "user3", "user4"}},
+		{name: "scenario/**/*_users.yml", want: []interface{}{"user1", "user2", "user4"}},
+	}
+	for _, d := range testdata {
+		t.Run(d.name, func(t *testing.T) {
+			if err := mongotest.UseFixture(d.name); err != nil {
+				t.Fatal(err)
+			}
+			if got := naturalOrderUserIDs(t); !reflect.DeepEqual(got, d.want) {
+				t.Errorf("all matched fixtures should be loaded in order (want: %v, got: %v)", d.want, got)
+			}
+		})
+	}
+}
+
+func TestUseFixtureWithUnmatchedGlob(t *testing.T) {
+	testdata := []string{"scenario/checkout/*.toml", "scenario/not_exist/"}
+	for _, name := range testdata {
+		t.Run(name, func(t *testing.T) {
+			err := mongotest.UseFixture(name)
+			if !errors.Is(err, mongotest.ErrFixtureNotFound) {
+				t.Errorf("should return ErrFixtureNotFound but got %v", err)
+			}
+		})
+	}
+}
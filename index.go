@@ -0,0 +1,270 @@
+package mongotest
+
+import (
+	"fmt"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// indexesKey is reserved key of index definitions in fixture.
+// Index definitions are written as map of index name and index specification.
+//
+//	users:
+//	  _indexes:
+//	    email_unique:
+//	      keys: {email: 1}
+//	      unique: true
+//	    company_age:
+//	      keys: [{company: 1}, {age: -1}]
+//
+// Keys of compound index must be written as list of single field map for keeping order.
+const indexesKey = "_indexes"
+
+func extractIndexes(cd CollectionData) ([]mongo.IndexModel, error) {
+	doc, ok := cd[indexesKey]
+	if !ok {
+		return nil, nil
+	}
+	delete(cd, indexesKey)
+	names := make([]string, 0, len(doc))
+	for name := range doc {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	models := make([]mongo.IndexModel, len(names))
+	for i, name := range names {
+		spec, ok := doc[name].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid index %q: %v", name, doc[name])
+		}
+		m, err := toIndexModel(name, spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %q: %s", name, err)
+		}
+		models[i] = m
+	}
+	return models, nil
+}
+
+func toIndexModel(name string, spec map[string]interface{}) (mongo.IndexModel, error) {
+	opts := options.Index().SetName(name)
+	var keys bson.D
+	var err error
+	for k, v := range spec {
+		switch k {
+		case "keys", "key":
+			keys, err = toIndexKeys(v)
+		case "name":
+			s, ok := v.(string)
+			if !ok {
+				return mongo.IndexModel{}, fmt.Errorf("invalid name: %v", v)
+			}
+			opts.SetName(s)
+		case "unique":
+			b, ok := v.(bool)
+			if !ok {
+				return mongo.IndexModel{}, fmt.Errorf("invalid unique: %v", v)
+			}
+			opts.SetUnique(b)
+		case "sparse":
+			b, ok := v.(bool)
+			if !ok {
+				return mongo.IndexModel{}, fmt.Errorf("invalid sparse: %v", v)
+			}
+			opts.SetSparse(b)
+		case "partialFilterExpression":
+			opts.SetPartialFilterExpression(v)
+		case "expireAfterSeconds":
+			n, ok := toInt32(v)
+			if !ok {
+				return mongo.IndexModel{}, fmt.Errorf("invalid expireAfterSeconds: %v", v)
+			}
+			opts.SetExpireAfterSeconds(n)
+		case "collation":
+			var c *options.Collation
+			c, err = toCollation(v)
+			opts.SetCollation(c)
+		case "weights":
+			opts.SetWeights(v)
+		case "default_language":
+			s, ok := v.(string)
+			if !ok {
+				return mongo.IndexModel{}, fmt.Errorf("invalid default_language: %v", v)
+			}
+			opts.SetDefaultLanguage(s)
+		case "2dsphereIndexVersion":
+			n, ok := toInt32(v)
+			if !ok {
+				return mongo.IndexModel{}, fmt.Errorf("invalid 2dsphereIndexVersion: %v", v)
+			}
+			opts.SetSphereVersion(n)
+		case "language_override":
+			s, ok := v.(string)
+			if !ok {
+				return mongo.IndexModel{}, fmt.Errorf("invalid language_override: %v", v)
+			}
+			opts.SetLanguageOverride(s)
+		case "textIndexVersion":
+			n, ok := toInt32(v)
+			if !ok {
+				return mongo.IndexModel{}, fmt.Errorf("invalid textIndexVersion: %v", v)
+			}
+			opts.SetTextVersion(n)
+		case "bits":
+			n, ok := toInt32(v)
+			if !ok {
+				return mongo.IndexModel{}, fmt.Errorf("invalid bits: %v", v)
+			}
+			opts.SetBits(n)
+		case "min":
+			f, ok := toFloat64(v)
+			if !ok {
+				return mongo.IndexModel{}, fmt.Errorf("invalid min: %v", v)
+			}
+			opts.SetMin(f)
+		case "max":
+			f, ok := toFloat64(v)
+			if !ok {
+				return mongo.IndexModel{}, fmt.Errorf("invalid max: %v", v)
+			}
+			opts.SetMax(f)
+		case "hidden":
+			b, ok := v.(bool)
+			if !ok {
+				return mongo.IndexModel{}, fmt.Errorf("invalid hidden: %v", v)
+			}
+			opts.SetHidden(b)
+		default:
+			return mongo.IndexModel{}, fmt.Errorf("unknown index option %q", k)
+		}
+		if err != nil {
+			return mongo.IndexModel{}, err
+		}
+	}
+	if len(keys) == 0 {
+		return mongo.IndexModel{}, fmt.Errorf("empty keys")
+	}
+	return mongo.IndexModel{Keys: keys, Options: opts}, nil
+}
+
+// toIndexKeys converts index keys to ordered document.
+// Key value is number (1 or -1) or string index type (text, 2dsphere, hashed and so on).
+func toIndexKeys(v interface{}) (bson.D, error) {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		// Order of fields in map is not kept, so compound index must be written as list.
+		if len(tv) > 1 {
+			return nil, fmt.Errorf("keys of compound index must be list of single field map: %v", v)
+		}
+		var keys bson.D
+		for f, fv := range tv {
+			kv, err := toIndexKeyValue(fv)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, bson.E{Key: f, Value: kv})
+		}
+		return keys, nil
+	case []interface{}:
+		var keys bson.D
+		for _, e := range tv {
+			m, ok := e.(map[string]interface{})
+			if !ok || len(m) != 1 {
+				return nil, fmt.Errorf("invalid keys: %v", v)
+			}
+			ks, err := toIndexKeys(m)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, ks...)
+		}
+		return keys, nil
+	default:
+		return nil, fmt.Errorf("invalid keys: %v", v)
+	}
+}
+
+func toIndexKeyValue(v interface{}) (interface{}, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	if n, ok := toInt32(v); ok {
+		return n, nil
+	}
+	return nil, fmt.Errorf("invalid key value: %v", v)
+}
+
+func toCollation(v interface{}) (*options.Collation, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid collation: %v", v)
+	}
+	c := &options.Collation{}
+	for k, v := range m {
+		var ok bool
+		switch k {
+		case "locale":
+			c.Locale, ok = v.(string)
+		case "caseLevel":
+			c.CaseLevel, ok = v.(bool)
+		case "caseFirst":
+			c.CaseFirst, ok = v.(string)
+		case "strength":
+			var n int32
+			n, ok = toInt32(v)
+			c.Strength = int(n)
+		case "numericOrdering":
+			c.NumericOrdering, ok = v.(bool)
+		case "alternate":
+			c.Alternate, ok = v.(string)
+		case "maxVariable":
+			c.MaxVariable, ok = v.(string)
+		case "normalization":
+			c.Normalization, ok = v.(bool)
+		case "backwards":
+			c.Backwards, ok = v.(bool)
+		case "version":
+			// ICU version of collation is set by server. (e.g. in index specification of mongodump)
+			continue
+		default:
+			return nil, fmt.Errorf("unknown collation option %q", k)
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid collation %s: %v", k, v)
+		}
+	}
+	return c, nil
+}
+
+func toInt32(v interface{}) (int32, bool) {
+	switch tv := v.(type) {
+	case int:
+		return int32(tv), true
+	case int32:
+		return tv, true
+	case int64:
+		return int32(tv), true
+	case float64:
+		return int32(tv), float64(int32(tv)) == tv
+	default:
+		return 0, false
+	}
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch tv := v.(type) {
+	case int:
+		return float64(tv), true
+	case int32:
+		return float64(tv), true
+	case int64:
+		return float64(tv), true
+	case float64:
+		return tv, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,90 @@
+package mongotest_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/pinzolo/mongotest"
+)
+
+type indexSpec struct {
+	Name               string `bson:"name"`
+	Key                bson.D `bson:"key"`
+	Unique             bool   `bson:"unique"`
+	ExpireAfterSeconds *int32 `bson:"expireAfterSeconds"`
+	Collation          *struct {
+		Locale   string `bson:"locale"`
+		Strength int32  `bson:"strength"`
+	} `bson:"collation"`
+}
+
+func listUserIndexes(t *testing.T) map[string]indexSpec {
+	t.Helper()
+	ctx, db := connectDatabase(t)
+	cur, err := db.Collection("users").Indexes().List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var specs []indexSpec
+	if err = cur.All(ctx, &specs); err != nil {
+		t.Fatal(err)
+	}
+	indexes := make(map[string]indexSpec, len(specs))
+	for _, spec := range specs {
+		indexes[spec.Name] = spec
+	}
+	return indexes
+}
+
+func TestUseFixtureWithIndexes(t *testing.T) {
+	err := mongotest.UseFixture("indexes/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cnt, err := mongotest.CountInt("users")
+	if err != nil {
+		t.Error(err)
+	}
+	if cnt != 2 {
+		t.Errorf("saved user count is invalid (want: %d, got: %d)", 2, cnt)
+	}
+
+	indexes := listUserIndexes(t)
+	for _, name := range []string{"email_unique", "company_age", "created_at_ttl", "name_text"} {
+		if _, ok := indexes[name]; !ok {
+			t.Errorf("index %s should be created", name)
+		}
+	}
+	if idx := indexes["email_unique"]; !idx.Unique {
+		t.Error("email_unique should be unique")
+	} else if idx.Collation == nil || idx.Collation.Locale != "en" || idx.Collation.Strength != 2 {
+		t.Errorf("collation of email_unique is invalid (%+v)", idx.Collation)
+	}
+	if idx := indexes["company_age"]; idx.Unique {
+		t.Error("company_age should not be unique")
+	}
+	want := bson.D{{Key: "company", Value: int32(1)}, {Key: "age", Value: int32(-1)}}
+	if got := indexes["company_age"].Key; !reflect.DeepEqual(got, want) {
+		t.Errorf("keys of company_age is invalid (want: %v, got: %v)", want, got)
+	}
+	if idx := indexes["created_at_ttl"]; idx.ExpireAfterSeconds == nil || *idx.ExpireAfterSeconds != 3600 {
+		t.Errorf("expireAfterSeconds of created_at_ttl is invalid (%v)", idx.ExpireAfterSeconds)
+	}
+}
+
+func TestUseFixtureWithViolatedUniqueIndex(t *testing.T) {
+	err := mongotest.UseFixture("indexes/duplicated_users")
+	if err == nil {
+		t.Error("should error when documents violate unique index")
+	}
+}
+
+func TestUseFixtureWithCompoundIndexKeysMap(t *testing.T) {
+	err := mongotest.UseFixture("indexes/unordered_keys")
+	if err == nil || !strings.Contains(err.Error(), "compound index") {
+		t.Errorf("should error when keys of compound index are written as map (got: %v)", err)
+	}
+}
@@ -0,0 +1,69 @@
+package mongotest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// findCollectionFiles returns fixture files of one-file-per-collection layout.
+// Each file in the directory holds CollectionData of the collection named by the file. (e.g. `users.yml` for users)
+// Files are returned in order of file name, and files of same collection are merged.
+func findCollectionFiles(name, dir string) ([]fixtureFile, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []fixtureFile
+	layout := make(map[string]bool)
+	for _, fi := range fis {
+		if fi.IsDir() || !isFixtureFile(fi.Name()) {
+			continue
+		}
+		cn := strings.TrimSuffix(fi.Name(), fixtureExt(fi.Name()))
+		files = append(files, fixtureFile{
+			path:       filepath.Join(dir, fi.Name()),
+			collection: cn,
+			layout:     layout,
+		})
+		layout[cn] = true
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: DataSet %q (directory %s has no fixture file)", ErrFixtureNotFound, name, dir)
+	}
+	return files, nil
+}
+
+// checkCollectionKey rejects document that is named as collection in the layout directory and looks like CollectionData,
+// because such document means that the file is written as DataSet by mistake. (e.g. `users:` at top level of `users.yml`)
+func checkCollectionKey(f fixtureFile, key string, doc DocData) error {
+	if !f.layout[key] || !isCollectionDataLike(doc) {
+		return nil
+	}
+	return &FixtureError{
+		File:       f.path,
+		Collection: f.collection,
+		Key:        key,
+		Err:        fmt.Errorf("key is collection name, file in collection directory must hold documents of %s only", f.collection),
+	}
+}
+
+// isCollectionDataLike reports whether all fields of document are documents.
+func isCollectionDataLike(doc DocData) bool {
+	if len(doc) == 0 {
+		return false
+	}
+	for _, v := range doc {
+		if _, ok := v.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
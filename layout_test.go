@@ -0,0 +1,66 @@
+package mongotest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestUseFixtureWithCollectionDirectory(t *testing.T) {
+	if err := mongotest.UseFixture("layout/checkout"); err != nil {
+		t.Fatal(err)
+	}
+	n, err := mongotest.Count("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("documents of YAML file should be loaded into collection named by file (want: 2, got: %d)", n)
+	}
+	company, err := mongotest.Find("companies", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := company["name"]; got != "bar company" {
+		t.Errorf("document of JSON file should be loaded into collection named by file (want: %q, got: %v)", "bar company", got)
+	}
+	user, err := mongotest.Find("users", "user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := user["company"]; got != "bar" {
+		t.Errorf("reference between collection files should be resolved (want: %q, got: %v)", "bar", got)
+	}
+}
+
+func TestUseFixtureWithDataSetInCollectionDirectory(t *testing.T) {
+	for _, d := range loadModes {
+		t.Run(d.name, func(t *testing.T) {
+			defer mongotest.Reconfigure(mongotest.Config{
+				Stream: d.stream,
+			})()
+			err := mongotest.UseFixture("layout/mistaken")
+			var fe *mongotest.FixtureError
+			if !errors.As(err, &fe) {
+				t.Fatalf("should return FixtureError but got %v", err)
+			}
+			if fe.Key != "users" {
+				t.Errorf("key of error is invalid (want: %q, got: %q)", "users", fe.Key)
+			}
+		})
+	}
+}
+
+func TestUseFixtureWithDocumentNamedAsCollection(t *testing.T) {
+	if err := mongotest.UseFixture("layout/tagged"); err != nil {
+		t.Fatal(err)
+	}
+	tag, err := mongotest.Find("tags", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tag["color"]; got != "red" {
+		t.Errorf("document named as collection should be loaded (want: %q, got: %v)", "red", got)
+	}
+}
@@ -0,0 +1,196 @@
+package mongotest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// manifestFileName is file name of manifest that defines fixture sets in fixture root directory.
+	manifestFileName = "fixtures.yml"
+	// fixtureSetPrefix is prefix of fixture name that means fixture set. (e.g. `@checkout`)
+	fixtureSetPrefix = "@"
+)
+
+// fixtureSet is named set of fixtures defined in manifest.
+// Set is written as list of fixture names, or as map that has fixtures and settings.
+//
+//	checkout:
+//	  - base/companies
+//	  - base/users
+//	  - checkout/carts
+//	checkout_with_times:
+//	  fixtures: ["@checkout", checkout/orders]
+//	  loadMode: Truncate
+//	  cleanMode: Drop
+//	  preInsertFuncs: [convertTimes]
+type fixtureSet struct {
+	Fixtures       []string      `yaml:"fixtures"`
+	LoadMode       LoadModeType  `yaml:"loadMode"`
+	CleanMode      CleanModeType `yaml:"cleanMode"`
+	PreInsertFuncs []string      `yaml:"preInsertFuncs"`
+}
+
+func (s *fixtureSet) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var names []string
+	if err := unmarshal(&names); err == nil {
+		s.Fixtures = names
+		return nil
+	}
+	type plain fixtureSet
+	return unmarshal((*plain)(s))
+}
+
+var preInsertFuncRegistry = struct {
+	sync.Mutex
+	m map[string]PreInsertFunc
+}{m: make(map[string]PreInsertFunc)}
+
+// RegisterPreInsertFunc registers PreInsertFunc with name, so that fixture sets in manifest can use it.
+func RegisterPreInsertFunc(name string, fn PreInsertFunc) {
+	preInsertFuncRegistry.Lock()
+	defer preInsertFuncRegistry.Unlock()
+	preInsertFuncRegistry.m[name] = fn
+}
+
+func registeredPreInsertFunc(name string) (PreInsertFunc, bool) {
+	preInsertFuncRegistry.Lock()
+	defer preInsertFuncRegistry.Unlock()
+	fn, ok := preInsertFuncRegistry.m[name]
+	return fn, ok
+}
+
+func manifestPath() string {
+	return filepath.Join(conf.fixtureRootDirAbs, manifestFileName)
+}
+
+func isFixtureSetName(name string) bool {
+	return strings.HasPrefix(name, fixtureSetPrefix)
+}
+
+// expandFixtureSets replaces fixture set names with fixture names defined in manifest.
+// Settings of fixture sets are applied to options, and values in options take priority over them.
+func expandFixtureSets(names []string, opts LoadOptions) ([]string, LoadOptions, error) {
+	hasSet := false
+	for _, name := range names {
+		if isFixtureSetName(name) {
+			hasSet = true
+		}
+	}
+	if !hasSet {
+		return names, opts, nil
+	}
+	sets, err := readManifest()
+	if err != nil {
+		return nil, opts, err
+	}
+	e := setExpander{sets: sets, visiting: make(map[string]bool), funcNames: make(map[string]bool)}
+	var expanded []string
+	for _, name := range names {
+		if !isFixtureSetName(name) {
+			expanded = append(expanded, name)
+			continue
+		}
+		ns, err := e.expand(strings.TrimPrefix(name, fixtureSetPrefix))
+		if err != nil {
+			return nil, opts, err
+		}
+		expanded = append(expanded, ns...)
+	}
+	if opts.LoadMode == loadModeEmpty {
+		opts.LoadMode = e.loadMode
+	}
+	if opts.CleanMode == cleanModeEmpty {
+		opts.CleanMode = e.cleanMode
+	}
+	opts.PreInsertFuncs = append(e.funcs, opts.PreInsertFuncs...)
+	return expanded, opts, nil
+}
+
+func readManifest() (map[string]fixtureSet, error) {
+	file := manifestPath()
+	bs, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: manifest %s does not exist", ErrFixtureNotFound, file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sets map[string]fixtureSet
+	if err = yaml.UnmarshalStrict(bs, &sets); err != nil {
+		return nil, &FixtureError{File: file, Line: yamlErrorLine(err), Err: err}
+	}
+	return sets, nil
+}
+
+// setExpander expands fixture sets with merging settings of them.
+type setExpander struct {
+	sets      map[string]fixtureSet
+	visiting  map[string]bool
+	loadMode  LoadModeType
+	cleanMode CleanModeType
+	funcs     []PreInsertFunc
+	funcNames map[string]bool
+}
+
+func (e *setExpander) expand(setName string) ([]string, error) {
+	set, ok := e.sets[setName]
+	if !ok {
+		return nil, fmt.Errorf("%w: fixture set %q in %s", ErrFixtureNotFound, setName, manifestPath())
+	}
+	if e.visiting[setName] {
+		return nil, &FixtureError{File: manifestPath(), Err: fmt.Errorf("circular fixture set %q", setName)}
+	}
+	e.visiting[setName] = true
+	defer delete(e.visiting, setName)
+	if err := e.merge(setName, set); err != nil {
+		return nil, &FixtureError{File: manifestPath(), Err: err}
+	}
+	var names []string
+	for _, name := range set.Fixtures {
+		if !isFixtureSetName(name) {
+			names = append(names, name)
+			continue
+		}
+		ns, err := e.expand(strings.TrimPrefix(name, fixtureSetPrefix))
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, ns...)
+	}
+	return names, nil
+}
+
+func (e *setExpander) merge(setName string, set fixtureSet) error {
+	if set.LoadMode != loadModeEmpty {
+		if e.loadMode != loadModeEmpty && e.loadMode != set.LoadMode {
+			return fmt.Errorf("fixture set %q: conflicting loadMode %s and %s", setName, e.loadMode, set.LoadMode)
+		}
+		e.loadMode = set.LoadMode
+	}
+	if set.CleanMode != cleanModeEmpty {
+		if e.cleanMode != cleanModeEmpty && e.cleanMode != set.CleanMode {
+			return fmt.Errorf("fixture set %q: conflicting cleanMode %s and %s", setName, e.cleanMode, set.CleanMode)
+		}
+		e.cleanMode = set.CleanMode
+	}
+	// Each PreInsertFunc is applied once even if it is used in multiple sets.
+	for _, name := range set.PreInsertFuncs {
+		if e.funcNames[name] {
+			continue
+		}
+		e.funcNames[name] = true
+		fn, ok := registeredPreInsertFunc(name)
+		if !ok {
+			return fmt.Errorf("fixture set %q: unknown PreInsertFunc %q", setName, name)
+		}
+		e.funcs = append(e.funcs, fn)
+	}
+	return nil
+}
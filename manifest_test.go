@@ -0,0 +1,66 @@
+package mongotest_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func markUser(collName string, doc mongotest.DocData) (mongotest.DocData, error) {
+	if collName == "users" {
+		doc["marked"] = true
+	}
+	return doc, nil
+}
+
+func TestUseFixtureWithFixtureSet(t *testing.T) {
+	if err := mongotest.UseFixture("@basic"); err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{"user1", "user2", "user3"}
+	if got := naturalOrderUserIDs(t); !reflect.DeepEqual(got, want) {
+		t.Errorf("all fixtures of set should be loaded in order (want: %v, got: %v)", want, got)
+	}
+}
+
+func TestUseFixtureWithFixtureSetSettings(t *testing.T) {
+	mongotest.RegisterPreInsertFunc("markUser", markUser)
+	if err := mongotest.UseFixture("@marked"); err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{"user1", "user2", "user3"}
+	if got := naturalOrderUserIDs(t); !reflect.DeepEqual(got, want) {
+		t.Errorf("fixtures of nested set should be loaded (want: %v, got: %v)", want, got)
+	}
+	user, err := mongotest.Find("users", "user3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := user["marked"]; got != true {
+		t.Errorf("PreInsertFunc of set should be applied (want: true, got: %v)", got)
+	}
+}
+
+func TestUseFixtureWithInvalidFixtureSet(t *testing.T) {
+	testdata := []struct {
+		name     string
+		notFound bool
+	}{
+		{name: "@not_exist", notFound: true},
+		{name: "@circular"},
+		{name: "@unknown_func"},
+	}
+	for _, d := range testdata {
+		t.Run(d.name, func(t *testing.T) {
+			err := mongotest.UseFixture(d.name)
+			if err == nil {
+				t.Fatal("should return error")
+			}
+			if got := errors.Is(err, mongotest.ErrFixtureNotFound); got != d.notFound {
+				t.Errorf("ErrFixtureNotFound should be returned only for undefined set (got: %v)", err)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package mongotest
 
 import (
 	"context"
+	"fmt"
+	"log"
 
 	"github.com/tkuchiki/parsetime"
 
@@ -85,10 +87,14 @@ func SimpleConvertTime(collectionName, fieldName string) PreInsertFunc {
 			}
 			t, err := p.Parse(sv)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("field %s: %w", fieldName, err)
 			}
 			value[fieldName] = t
 		}
 		return value, nil
 	}
 }
+
+func warnf(format string, args ...interface{}) {
+	log.Printf("[mongotest] WARN: "+format, args...)
+}
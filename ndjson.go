@@ -0,0 +1,76 @@
+package mongotest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ndjsonDocReader reads documents from newline-delimited JSON fixture line by line.
+// Each line is a Extended JSON document like output of mongoexport, and all documents belong to a collection.
+// Key of document is string representation of `_id`. (hex string for ObjectID)
+type ndjsonDocReader struct {
+	rc   io.ReadCloser
+	r    *bufio.Reader
+	coll string
+	line int
+}
+
+func newNDJSONDocReader(rc io.ReadCloser, collName string) *ndjsonDocReader {
+	return &ndjsonDocReader{rc: rc, r: bufio.NewReader(rc), coll: collName}
+}
+
+func (r *ndjsonDocReader) read() (string, string, DocData, error) {
+	for {
+		bs, err := r.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return "", "", nil, err
+		}
+		if len(bs) == 0 && err == io.EOF {
+			return "", "", nil, io.EOF
+		}
+		r.line++
+		bs = bytes.TrimSpace(bs)
+		if len(bs) == 0 {
+			continue
+		}
+		var m map[string]interface{}
+		if err = bson.UnmarshalExtJSON(bs, false, &m); err != nil {
+			return "", "", nil, &FixtureError{Line: r.line, Collection: r.coll, Err: err}
+		}
+		doc := normalizeValue(m).(map[string]interface{})
+		id, ok := doc["_id"]
+		if !ok {
+			return "", "", nil, &FixtureError{Line: r.line, Collection: r.coll, Err: errors.New("no _id")}
+		}
+		doc["_id"] = exportedID{value: id}
+		return r.coll, documentKey(id), doc, nil
+	}
+}
+
+func (r *ndjsonDocReader) collections() []string {
+	return []string{r.coll}
+}
+
+func (r *ndjsonDocReader) Close() error {
+	return r.rc.Close()
+}
+
+// exportedID is _id written in exported document (NDJSON and BSON).
+// Reader keeps it in document, so it is used as _id instead of key even if document is merged with other fixtures.
+type exportedID struct {
+	value interface{}
+}
+
+// documentKey returns key of document that has given _id.
+func documentKey(id interface{}) string {
+	if oid, ok := id.(primitive.ObjectID); ok {
+		return oid.Hex()
+	}
+	return fmt.Sprint(id)
+}
@@ -0,0 +1,80 @@
+package mongotest_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestUseFixtureNDJSONFormat(t *testing.T) {
+	for _, d := range loadModes {
+		t.Run(d.name, func(t *testing.T) {
+			defer mongotest.Reconfigure(mongotest.Config{
+				Stream: d.stream,
+			})()
+			if err := mongotest.UseFixture("ndjson/users"); err != nil {
+				t.Fatal(err)
+			}
+			oid, _ := primitive.ObjectIDFromHex("5c2cb0d0e7a0c1a1b2c3d4e5")
+			saved, err := mongotest.Find("users", oid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]interface{}{
+				"_id":        oid,
+				"name":       "user1",
+				"age":        int32(30),
+				"created_at": createdAtPrimitive,
+			}
+			if !reflect.DeepEqual(saved, want) {
+				t.Errorf("saved user is invalid. (%v)", diffMap(saved, want))
+			}
+			saved, err = mongotest.Find("users", "user2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want = map[string]interface{}{
+				"_id":     "user2",
+				"name":    "user2",
+				"tags":    primitive.A{"a", "b"},
+				"address": primitive.M{"city": "Tokyo"},
+			}
+			if !reflect.DeepEqual(saved, want) {
+				t.Errorf("saved user is invalid. (%v)", diffMap(saved, want))
+			}
+		})
+	}
+}
+
+func TestUseFixtureNDJSONFormatWithBrokenLine(t *testing.T) {
+	err := mongotest.UseFixture("ndjson/broken_users")
+	var fe *mongotest.FixtureError
+	if !errors.As(err, &fe) {
+		t.Fatalf("should return FixtureError but got %v", err)
+	}
+	if got := filepath.Base(fe.File); got != "broken_users.ndjson" {
+		t.Errorf("file of error is invalid (want: %s, got: %s)", "broken_users.ndjson", got)
+	}
+	if fe.Line != 2 {
+		t.Errorf("line of error is invalid (want: %d, got: %d)", 2, fe.Line)
+	}
+}
+
+func TestUseFixtureNDJSONFormatOverridden(t *testing.T) {
+	if err := mongotest.UseFixture("ndjson/users", "ndjson/override_users"); err != nil {
+		t.Fatal(err)
+	}
+	oid, _ := primitive.ObjectIDFromHex("5c2cb0d0e7a0c1a1b2c3d4e5")
+	saved, err := mongotest.Find("users", oid)
+	if err != nil {
+		t.Fatalf("_id of exported document should be kept after merging (%s)", err)
+	}
+	if got := saved["name"]; got != "overridden" {
+		t.Errorf("document should be overridden (want: %q, got: %v)", "overridden", got)
+	}
+}
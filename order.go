@@ -0,0 +1,95 @@
+package mongotest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DocumentOrderType is order of inserting documents in collection.
+type DocumentOrderType string
+
+const (
+	// DocumentOrderFile means that documents are inserted in order written in fixture file. (default)
+	// When fixtures are merged, documents of former fixture are inserted first.
+	DocumentOrderFile = DocumentOrderType("File")
+	// DocumentOrderKey means that documents are inserted in order of document key.
+	DocumentOrderKey   = DocumentOrderType("Key")
+	documentOrderEmpty = DocumentOrderType("")
+)
+
+func validateDocumentOrder(order DocumentOrderType) error {
+	switch order {
+	case DocumentOrderFile, DocumentOrderKey:
+		return nil
+	default:
+		return fmt.Errorf("%w: invalid DocumentOrder %q", ErrInvalidConfig, order)
+	}
+}
+
+// toDocumentOrder converts order written in fixture (case insensitive) to DocumentOrderType.
+func toDocumentOrder(v interface{}) (DocumentOrderType, error) {
+	s, ok := v.(string)
+	if !ok {
+		return documentOrderEmpty, fmt.Errorf("invalid order: %v", v)
+	}
+	for _, order := range []DocumentOrderType{DocumentOrderFile, DocumentOrderKey} {
+		if strings.EqualFold(s, string(order)) {
+			return order, nil
+		}
+	}
+	return documentOrderEmpty, fmt.Errorf("invalid order: %v", v)
+}
+
+// dataOrder holds order of documents in fixture.
+//
+//	key: collection name
+//	value: document keys in order of appearance
+type dataOrder map[string][]string
+
+func mergeDataOrder(orders []dataOrder) dataOrder {
+	merged := make(dataOrder)
+	seen := make(map[string]map[string]bool)
+	for _, order := range orders {
+		for cn, keys := range order {
+			if _, ok := seen[cn]; !ok {
+				seen[cn] = make(map[string]bool, len(keys))
+			}
+			for _, key := range keys {
+				if !seen[cn][key] {
+					seen[cn][key] = true
+					merged[cn] = append(merged[cn], key)
+				}
+			}
+		}
+	}
+	return merged
+}
+
+// orderedKeys returns document keys of collection data in given order.
+// Keys not contained in order of appearance are placed last in order of key.
+func orderedKeys(cd CollectionData, keys []string, order DocumentOrderType) []string {
+	ordered := make([]string, 0, len(cd))
+	if order == DocumentOrderFile {
+		for _, key := range keys {
+			if _, ok := cd[key]; ok {
+				ordered = append(ordered, key)
+			}
+		}
+	}
+	if len(ordered) == len(cd) {
+		return ordered
+	}
+	added := make(map[string]bool, len(ordered))
+	for _, key := range ordered {
+		added[key] = true
+	}
+	rest := make([]string, 0, len(cd)-len(ordered))
+	for key := range cd {
+		if !added[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(ordered, rest...)
+}
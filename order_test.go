@@ -0,0 +1,59 @@
+package mongotest_test
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pinzolo/mongotest"
+)
+
+// naturalOrderUserIDs returns _id of users in natural order.
+func naturalOrderUserIDs(t *testing.T) []interface{} {
+	t.Helper()
+	ctx, db := connectDatabase(t)
+	opts := options.Find().SetSort(bson.D{{Key: "$natural", Value: 1}})
+	cur, err := db.Collection("users").Find(ctx, bson.M{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var docs []bson.M
+	if err = cur.All(ctx, &docs); err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc["_id"]
+	}
+	return ids
+}
+
+func TestUseFixtureWithDocumentOrder(t *testing.T) {
+	testdata := []struct {
+		name  string
+		order mongotest.DocumentOrderType
+		want  []interface{}
+	}{
+		{name: "order/yaml_users", want: []interface{}{"user3", "user1", "user2"}},
+		{name: "order/json_users", want: []interface{}{"user3", "user1", "user2"}},
+		{name: "order/yaml_users", order: mongotest.DocumentOrderKey, want: []interface{}{"user1", "user2", "user3"}},
+		{name: "order/sorted_users", want: []interface{}{"user1", "user2", "user3"}},
+	}
+	for _, d := range testdata {
+		t.Run(d.name, func(t *testing.T) {
+			defer mongotest.Reconfigure(mongotest.Config{
+				DocumentOrder: d.order,
+			})()
+			for i := 0; i < 3; i++ {
+				if err := mongotest.UseFixture(d.name); err != nil {
+					t.Fatal(err)
+				}
+				if got := naturalOrderUserIDs(t); !reflect.DeepEqual(got, d.want) {
+					t.Errorf("documents should be inserted in order (want: %v, got: %v)", d.want, got)
+				}
+			}
+		})
+	}
+}
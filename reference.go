@@ -0,0 +1,91 @@
+package mongotest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// refKey is key of reference value.
+// Reference value is written as `{$ref: <collection name>.<document key>}` in fixture,
+// and it is replaced with final _id value of referenced document.
+const refKey = "$ref"
+
+func resolveReferences(docsMap map[string][]fixtureDoc) error {
+	ids := make(map[string]map[string]interface{}, len(docsMap))
+	for cn, docs := range docsMap {
+		m := make(map[string]interface{}, len(docs))
+		for _, doc := range docs {
+			m[doc.key] = doc.data["_id"]
+		}
+		ids[cn] = m
+	}
+	var errs MultiError
+	for cn, docs := range docsMap {
+		for _, doc := range docs {
+			for k, v := range doc.data {
+				rv, err := resolveValue(ids, v)
+				if err != nil {
+					errs = append(errs, &FixtureError{File: doc.file, Collection: cn, Key: doc.key, Err: fmt.Errorf("field %s: %w", k, err)})
+					continue
+				}
+				doc.data[k] = rv
+			}
+		}
+	}
+	return errs.err()
+}
+
+func resolveValue(ids map[string]map[string]interface{}, v interface{}) (interface{}, error) {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := referenceOf(tv); ok {
+			return lookupReference(ids, ref)
+		}
+		for k, v := range tv {
+			rv, err := resolveValue(ids, v)
+			if err != nil {
+				return nil, err
+			}
+			tv[k] = rv
+		}
+		return tv, nil
+	case []interface{}:
+		for i, v := range tv {
+			rv, err := resolveValue(ids, v)
+			if err != nil {
+				return nil, err
+			}
+			tv[i] = rv
+		}
+		return tv, nil
+	default:
+		return v, nil
+	}
+}
+
+func referenceOf(m map[string]interface{}) (string, bool) {
+	if len(m) != 1 {
+		return "", false
+	}
+	ref, ok := m[refKey].(string)
+	return ref, ok
+}
+
+func lookupReference(ids map[string]map[string]interface{}, ref string) (interface{}, error) {
+	// Collection name may contain dots, so the longest matched collection name is used.
+	var collName string
+	for cn := range ids {
+		if strings.HasPrefix(ref, cn+".") && len(cn) > len(collName) {
+			collName = cn
+		}
+	}
+	if collName == "" {
+		return nil, fmt.Errorf("dangling reference %q: collection not found", ref)
+	}
+	key := strings.TrimPrefix(ref, collName+".")
+	id, ok := ids[collName][key]
+	if !ok {
+		return nil, fmt.Errorf("dangling reference %q: document not found", ref)
+	}
+	return id, nil
+}
@@ -0,0 +1,74 @@
+package mongotest_test
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/pinzolo/mongotest"
+)
+
+var companyIDs = map[string]string{
+	"foo": "5c2cb0d0e7a0c1a1b2c3d4e5",
+	"bar": "5c2cb0d0e7a0c1a1b2c3d4e6",
+}
+
+func convertCompanyID(collName string, doc mongotest.DocData) (mongotest.DocData, error) {
+	if collName != "companies" {
+		return doc, nil
+	}
+	id, _ := doc.StringValue("_id")
+	oid, err := primitive.ObjectIDFromHex(companyIDs[id])
+	if err != nil {
+		return nil, err
+	}
+	doc["_id"] = oid
+	return doc, nil
+}
+
+func TestUseFixtureWithReference(t *testing.T) {
+	err := mongotest.UseFixture("refs/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := mongotest.Find("users", "user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := saved["company"]; got != "bar" {
+		t.Errorf("reference should be resolved to _id of referenced document (want: %q, got: %v)", "bar", got)
+	}
+	want := primitive.A{"user1"}
+	if got := saved["friends"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("reference in array should be resolved (want: %v, got: %v)", want, got)
+	}
+}
+
+func TestUseFixtureWithReferenceToConvertedID(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		PreInsertFuncs: []mongotest.PreInsertFunc{
+			mongotest.SimpleConvertTime("users", "created_at"),
+			convertCompanyID,
+		},
+	})()
+	err := mongotest.UseFixture("refs/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := mongotest.Find("users", "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := primitive.ObjectIDFromHex(companyIDs["foo"])
+	if got := saved["company"]; got != want {
+		t.Errorf("reference should be resolved to converted _id (want: %v, got: %v)", want, got)
+	}
+}
+
+func TestUseFixtureWithDanglingReference(t *testing.T) {
+	err := mongotest.UseFixture("refs/dangling")
+	if err == nil {
+		t.Error("should error when fixture has dangling reference")
+	}
+}
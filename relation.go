@@ -0,0 +1,149 @@
+package mongotest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// relation is parsed relation between collections.
+type relation struct {
+	collection    string
+	field         string
+	refCollection string
+	refField      string
+}
+
+func (r relation) String() string {
+	return fmt.Sprintf("%s.%s -> %s.%s", r.collection, r.field, r.refCollection, r.refField)
+}
+
+func parseRelations(rels []string) ([]relation, error) {
+	parsed := make([]relation, len(rels))
+	for i, s := range rels {
+		r, err := parseRelation(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = r
+	}
+	return parsed, nil
+}
+
+func parseRelation(s string) (relation, error) {
+	sides := strings.Split(s, "->")
+	if len(sides) != 2 {
+		return relation{}, fmt.Errorf("%w: invalid relation %q", ErrInvalidConfig, s)
+	}
+	coll, field, ok := splitFieldPath(sides[0])
+	if !ok {
+		return relation{}, fmt.Errorf("%w: invalid relation %q", ErrInvalidConfig, s)
+	}
+	refColl, refField, ok := splitFieldPath(sides[1])
+	if !ok {
+		return relation{}, fmt.Errorf("%w: invalid relation %q", ErrInvalidConfig, s)
+	}
+	return relation{
+		collection:    coll,
+		field:         field,
+		refCollection: refColl,
+		refField:      refField,
+	}, nil
+}
+
+// splitFieldPath splits `<collection>.<field>` into collection name and field path.
+// Field path may contain dots for nested document.
+func splitFieldPath(s string) (string, string, bool) {
+	s = strings.TrimSpace(s)
+	i := strings.Index(s, ".")
+	if i <= 0 || i == len(s)-1 {
+		return "", "", false
+	}
+	return s[:i], s[i+1:], true
+}
+
+func validateRelations(docsMap map[string][]fixtureDoc) error {
+	var errs MultiError
+	for _, r := range conf.relations {
+		refs := make(map[string]bool)
+		for _, doc := range docsMap[r.refCollection] {
+			for _, v := range fieldValues(doc.data, r.refField) {
+				refs[valueKey(v)] = true
+			}
+		}
+		docs := sortedDocs(docsMap[r.collection])
+		for _, doc := range docs {
+			for _, v := range fieldValues(doc.data, r.field) {
+				if !refs[valueKey(v)] {
+					errs = append(errs, &FixtureError{
+						File:       doc.file,
+						Collection: r.collection,
+						Key:        doc.key,
+						Err:        fmt.Errorf("dangling reference %v in %s", v, r),
+					})
+				}
+			}
+		}
+	}
+	return errs.err()
+}
+
+// fieldValues returns values of given dotted field path.
+// When array is found in path, values of all elements are returned.
+func fieldValues(v interface{}, path string) []interface{} {
+	if path == "" {
+		if a, ok := v.([]interface{}); ok {
+			return a
+		}
+		if v == nil {
+			return nil
+		}
+		return []interface{}{v}
+	}
+	name, rest := path, ""
+	if i := strings.Index(path, "."); i >= 0 {
+		name, rest = path[:i], path[i+1:]
+	}
+	switch tv := v.(type) {
+	case DocData:
+		return fieldValues(tv[name], rest)
+	case map[string]interface{}:
+		return fieldValues(tv[name], rest)
+	case []interface{}:
+		var vs []interface{}
+		for _, e := range tv {
+			vs = append(vs, fieldValues(e, path)...)
+		}
+		return vs
+	default:
+		return nil
+	}
+}
+
+// valueKey returns comparable key of value.
+// Numbers are compared as float64 like MongoDB does.
+func valueKey(v interface{}) string {
+	switch tv := v.(type) {
+	case int:
+		return fmt.Sprintf("number:%v", float64(tv))
+	case int32:
+		return fmt.Sprintf("number:%v", float64(tv))
+	case int64:
+		return fmt.Sprintf("number:%v", float64(tv))
+	case float32:
+		return fmt.Sprintf("number:%v", float64(tv))
+	case float64:
+		return fmt.Sprintf("number:%v", tv)
+	default:
+		return fmt.Sprintf("%T:%v", v, v)
+	}
+}
+
+func sortedDocs(docs []fixtureDoc) []fixtureDoc {
+	sorted := make([]fixtureDoc, len(docs))
+	copy(sorted, docs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].key < sorted[j].key
+	})
+	return sorted
+}
@@ -0,0 +1,46 @@
+package mongotest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestUseFixtureWithRelations(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		Relations: []string{"users.company -> companies._id"},
+	})()
+	err := mongotest.UseFixture("admin_users")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUseFixtureWithDanglingRelations(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		Relations: []string{"users.company -> companies._id"},
+	})()
+	err := mongotest.UseFixture("relations/broken")
+	if err == nil {
+		t.Fatal("should error when fixture has dangling reference")
+	}
+	for _, key := range []string{"user2", "user3"} {
+		if !strings.Contains(err.Error(), "document: "+key) {
+			t.Errorf("error should report dangling reference of %s (got: %s)", key, err)
+		}
+	}
+	if strings.Contains(err.Error(), "document: user1") {
+		t.Errorf("error should not report valid reference of user1 (got: %s)", err)
+	}
+}
+
+func TestUseFixtureWithInvalidRelations(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		Relations: []string{"users.company companies._id"},
+	})()
+	err := mongotest.UseFixture("admin_users")
+	if err == nil {
+		t.Error("should error when relation is invalid")
+	}
+}
@@ -0,0 +1,75 @@
+package mongotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// schemaDirName is directory name of JSON Schema files in fixture root directory.
+// JSON Schema file is named as `<collection name>.json`.
+const schemaDirName = "schemas"
+
+func validateSchemas(docsMap map[string][]fixtureDoc) error {
+	var errs MultiError
+	for cn, docs := range docsMap {
+		schema, err := loadSchema(cn)
+		if err != nil {
+			errs = errs.add(err)
+			continue
+		}
+		if schema == nil {
+			continue
+		}
+		for _, doc := range sortedDocs(docs) {
+			errs = errs.add(validateSchema(schema, cn, doc))
+		}
+	}
+	return errs.err()
+}
+
+// loadSchema returns JSON Schema for given collection.
+// Returns nil when schema file is not configured and not found in schema directory.
+func loadSchema(collName string) (*gojsonschema.Schema, error) {
+	file, ok := conf.Schemas[collName]
+	if ok {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, err
+		}
+		file = abs
+	} else {
+		file = filepath.Join(conf.fixtureRootDirAbs, schemaDirName, collName+".json")
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			return nil, nil
+		}
+	}
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewReferenceLoader("file://" + filepath.ToSlash(file)))
+	if err != nil {
+		return nil, &FixtureError{File: file, Collection: collName, Err: fmt.Errorf("invalid JSON Schema: %w", err)}
+	}
+	return schema, nil
+}
+
+// validateSchema validates document with JSON Schema, and returns MultiError of FixtureError if invalid.
+func validateSchema(schema *gojsonschema.Schema, collName string, doc fixtureDoc) error {
+	bs, err := json.Marshal(doc.data)
+	if err != nil {
+		return withLocation(MultiError{err}, doc.file, collName, doc.key)
+	}
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(bs))
+	if err != nil {
+		return withLocation(MultiError{err}, doc.file, collName, doc.key)
+	}
+	if result.Valid() {
+		return nil
+	}
+	var errs MultiError
+	for _, re := range result.Errors() {
+		errs = append(errs, fmt.Errorf("schema violation: %s", re))
+	}
+	return withLocation(errs, doc.file, collName, doc.key)
+}
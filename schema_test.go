@@ -0,0 +1,61 @@
+package mongotest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestUseFixtureWithSchemaDir(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		FixtureRootDir: "testdata/schema",
+	})()
+	err := mongotest.UseFixture("valid_users")
+	if err != nil {
+		t.Error(err)
+	}
+	err = mongotest.UseFixture("invalid_users")
+	if err == nil {
+		t.Fatal("should error when document violates schema")
+	}
+	if !strings.Contains(err.Error(), "document: user2") {
+		t.Errorf("error should report invalid document (got: %s)", err)
+	}
+	if strings.Contains(err.Error(), "document: user1") {
+		t.Errorf("error should not report valid document (got: %s)", err)
+	}
+}
+
+func TestUseFixtureWithConfiguredSchemas(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		Schemas: map[string]string{
+			"users": "testdata/schema/strict_users.json",
+		},
+	})()
+	err := mongotest.UseFixture("admin_users")
+	if err == nil {
+		t.Error("should error when document violates configured schema")
+	}
+}
+
+func TestUseFixtureWithBrokenSchemas(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		Schemas: map[string]string{
+			"users":     "testdata/schema/broken_schema.json",
+			"companies": "testdata/schema/broken_schema.json",
+		},
+	})()
+	err := mongotest.UseFixture("admin_users")
+	if err == nil {
+		t.Fatal("should error when schema is invalid")
+	}
+	for _, cn := range []string{"users", "companies"} {
+		if !strings.Contains(err.Error(), "collection: "+cn) {
+			t.Errorf("error should report invalid schema of %s (got: %s)", cn, err)
+		}
+	}
+	if !strings.Contains(err.Error(), "broken_schema.json") {
+		t.Errorf("error should report schema file (got: %s)", err)
+	}
+}
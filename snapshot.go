@@ -0,0 +1,278 @@
+package mongotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// snapshotPrefix is prefix of shadow collection names that hold snapshot documents.
+// Shadow collection is named as `<prefix><snapshot name>.<collection name>`.
+const snapshotPrefix = "mongotest_snapshot."
+
+// snapshot is copied state of fixture-loaded collections.
+type snapshot struct {
+	database    string
+	collections []snapshotCollection
+}
+
+type snapshotCollection struct {
+	name    string
+	exists  bool
+	options bson.Raw
+	indexes []bson.D
+	state   loadedState
+	tracked bool
+}
+
+var snapshots = struct {
+	sync.Mutex
+	m map[string]snapshot
+}{m: make(map[string]snapshot)}
+
+// Snapshot copies documents of the fixture-loaded collections into shadow collections with given name.
+// Options and indexes of collections are also saved, and they are restored by Restore.
+// Snapshot information is held in process, so Restore must be called in same process.
+func Snapshot(ctx context.Context, name string) error {
+	if err := validateConfig(); err != nil {
+		return err
+	}
+	if err := validateSnapshotName(name); err != nil {
+		return err
+	}
+	names := fixtureCollectionNames()
+	if len(names) == 0 {
+		return errors.New("no fixture-loaded collection")
+	}
+	ctx, client, cancel, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	db := client.Database(conf.Database)
+	snap := snapshot{database: conf.Database}
+	for _, cn := range names {
+		sc, err := snapshotOf(ctx, db, name, cn)
+		if err != nil {
+			return withLocation(err, "", cn, "")
+		}
+		snap.collections = append(snap.collections, sc)
+	}
+	snapshots.Lock()
+	defer snapshots.Unlock()
+	snapshots.m[name] = snap
+	return nil
+}
+
+func snapshotOf(ctx context.Context, db *mongo.Database, name, collName string) (snapshotCollection, error) {
+	sc := snapshotCollection{name: collName}
+	specs, err := db.ListCollectionSpecifications(ctx, bson.M{"name": collName})
+	if err != nil {
+		return sc, err
+	}
+	shadow := db.Collection(shadowCollectionName(name, collName))
+	if err = shadow.Drop(ctx); err != nil {
+		return sc, err
+	}
+	if len(specs) == 0 {
+		return sc, nil
+	}
+	sc.exists = true
+	sc.options = specs[0].Options
+	sc.indexes, err = listIndexes(ctx, db.Collection(collName))
+	if err != nil {
+		return sc, err
+	}
+	err = copyCollection(ctx, db.Collection(collName), shadow.Name())
+	if err != nil {
+		return sc, err
+	}
+	loadedCollections.Lock()
+	sc.state, sc.tracked = loadedCollections.states[loadedKey(collName)]
+	loadedCollections.Unlock()
+	return sc, nil
+}
+
+// Restore restores documents, options and indexes of collections from snapshot that has given name.
+// Collections are recreated, so options and indexes changed after snapshot are discarded.
+// Collections that did not exist at snapshot are dropped.
+func Restore(ctx context.Context, name string) error {
+	if err := validateConfig(); err != nil {
+		return err
+	}
+	snapshots.Lock()
+	snap, ok := snapshots.m[name]
+	snapshots.Unlock()
+	if !ok {
+		return fmt.Errorf("snapshot %q not found", name)
+	}
+	if snap.database != conf.Database {
+		return fmt.Errorf("snapshot %q is taken in database %s", name, snap.database)
+	}
+	ctx, client, cancel, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	db := client.Database(conf.Database)
+	for _, sc := range snap.collections {
+		if err = restoreCollection(ctx, db, name, sc); err != nil {
+			return withLocation(err, "", sc.name, "")
+		}
+	}
+	return nil
+}
+
+func restoreCollection(ctx context.Context, db *mongo.Database, name string, sc snapshotCollection) error {
+	collection := db.Collection(sc.name)
+	loadedCollections.Lock()
+	delete(loadedCollections.states, loadedKey(sc.name))
+	loadedCollections.Unlock()
+	if err := collection.Drop(ctx); err != nil || !sc.exists {
+		return err
+	}
+	cmd := bson.D{{Key: "create", Value: sc.name}}
+	elems, err := sc.options.Elements()
+	if err != nil {
+		return err
+	}
+	for _, e := range elems {
+		cmd = append(cmd, bson.E{Key: e.Key(), Value: e.Value()})
+	}
+	if err = db.RunCommand(ctx, cmd).Err(); err != nil {
+		return err
+	}
+	err = copyCollection(ctx, db.Collection(shadowCollectionName(name, sc.name)), sc.name)
+	if err != nil {
+		return err
+	}
+	if len(sc.indexes) > 0 {
+		cmd = bson.D{{Key: "createIndexes", Value: sc.name}, {Key: "indexes", Value: sc.indexes}}
+		if err = db.RunCommand(ctx, cmd).Err(); err != nil {
+			return err
+		}
+	}
+	if sc.tracked {
+		loadedCollections.Lock()
+		loadedCollections.states[loadedKey(sc.name)] = sc.state
+		loadedCollections.Unlock()
+	}
+	return nil
+}
+
+// DropSnapshot drops shadow collections of snapshot that has given name.
+func DropSnapshot(ctx context.Context, name string) error {
+	if err := validateConfig(); err != nil {
+		return err
+	}
+	snapshots.Lock()
+	snap, ok := snapshots.m[name]
+	delete(snapshots.m, name)
+	snapshots.Unlock()
+	if !ok {
+		return fmt.Errorf("snapshot %q not found", name)
+	}
+	ctx, client, cancel, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	db := client.Database(snap.database)
+	for _, sc := range snap.collections {
+		if err = db.Collection(shadowCollectionName(name, sc.name)).Drop(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSnapshotName rejects name that makes ambiguous shadow collection name.
+// (e.g. snapshot `a.b` of users and snapshot `a` of `b.users`)
+func validateSnapshotName(name string) error {
+	if name == "" || strings.ContainsAny(name, ".$\x00") {
+		return fmt.Errorf("invalid snapshot name %q", name)
+	}
+	return nil
+}
+
+func shadowCollectionName(name, collName string) string {
+	return snapshotPrefix + name + "." + collName
+}
+
+// copyCollection replaces documents of target collection with documents of given collection by $out.
+func copyCollection(ctx context.Context, collection *mongo.Collection, target string) error {
+	pipeline := mongo.Pipeline{{{Key: "$out", Value: target}}}
+	opts := options.Aggregate().SetAllowDiskUse(true).SetBypassDocumentValidation(true)
+	cur, err := collection.Aggregate(ctx, pipeline, opts)
+	if err != nil {
+		return err
+	}
+	return cur.Close(ctx)
+}
+
+// listIndexes returns index specifications of collection except _id index.
+func listIndexes(ctx context.Context, collection *mongo.Collection) ([]bson.D, error) {
+	cur, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var specs []bson.D
+	if err = cur.All(ctx, &specs); err != nil {
+		return nil, err
+	}
+	indexes := make([]bson.D, 0, len(specs))
+	for _, spec := range specs {
+		var idx bson.D
+		isID := false
+		for _, e := range spec {
+			if e.Key == "name" && e.Value == "_id_" {
+				isID = true
+			}
+			if e.Key != "ns" {
+				idx = append(idx, e)
+			}
+		}
+		if !isID {
+			indexes = append(indexes, idx)
+		}
+	}
+	return indexes, nil
+}
+
+// fixtureCollections holds collections loaded by fixture in this process.
+//
+//	key: `<database>.<collection>`
+var fixtureCollections = struct {
+	sync.Mutex
+	m map[string]bool
+}{m: make(map[string]bool)}
+
+func markFixtureCollections(names map[string]bool) {
+	fixtureCollections.Lock()
+	defer fixtureCollections.Unlock()
+	for cn := range names {
+		fixtureCollections.m[loadedKey(cn)] = true
+	}
+}
+
+// fixtureCollectionNames returns sorted names of fixture-loaded collections in configured database.
+func fixtureCollectionNames() []string {
+	fixtureCollections.Lock()
+	defer fixtureCollections.Unlock()
+	prefix := conf.Database + "."
+	var names []string
+	for key := range fixtureCollections.m {
+		if strings.HasPrefix(key, prefix) {
+			names = append(names, strings.TrimPrefix(key, prefix))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,97 @@
+package mongotest_test
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestSnapshotAndRestore(t *testing.T) {
+	ctx := context.Background()
+	if err := mongotest.UseFixture("indexes/users"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mongotest.Snapshot(ctx, "base"); err != nil {
+		t.Fatal(err)
+	}
+	defer mongotest.DropSnapshot(ctx, "base")
+
+	insertUser(t, "modified")
+	assertUserCount(t, 3)
+	if err := mongotest.Restore(ctx, "base"); err != nil {
+		t.Fatal(err)
+	}
+	assertUserCount(t, 2)
+
+	if err := mongotest.UseFixture("modes/duplicated_emails"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mongotest.Restore(ctx, "base"); err != nil {
+		t.Fatal(err)
+	}
+	assertUserCount(t, 2)
+	saved, err := mongotest.Find("users", "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := saved["email"]; got != "user1@example.com" {
+		t.Errorf("document should be restored (want: %q, got: %v)", "user1@example.com", got)
+	}
+}
+
+func TestRestoreDiscardsChangesAfterSnapshot(t *testing.T) {
+	ctx := context.Background()
+	if err := mongotest.UseFixture("modes/duplicated_emails"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mongotest.Snapshot(ctx, "duplicated"); err != nil {
+		t.Fatal(err)
+	}
+	defer mongotest.DropSnapshot(ctx, "duplicated")
+
+	if err := mongotest.UseFixture("indexes/users"); err != nil {
+		t.Fatal(err)
+	}
+	_, db := connectDatabase(t)
+	cmd := bson.D{
+		{Key: "collMod", Value: "users"},
+		{Key: "validator", Value: bson.M{"$jsonSchema": bson.M{"required": bson.A{"age"}}}},
+	}
+	if err := db.RunCommand(ctx, cmd).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mongotest.Restore(ctx, "duplicated"); err != nil {
+		t.Fatal(err)
+	}
+	assertUserCount(t, 2)
+
+	specs, err := db.Collection("users").Indexes().ListSpecifications(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, spec := range specs {
+		if spec.Name != "_id_" {
+			t.Errorf("index %s added after snapshot should be dropped", spec.Name)
+		}
+	}
+	if _, err = db.Collection("users").InsertOne(ctx, bson.M{"_id": "user3"}); err != nil {
+		t.Errorf("validator added after snapshot should be removed (%v)", err)
+	}
+}
+
+func TestRestoreUnknownSnapshot(t *testing.T) {
+	if err := mongotest.Restore(context.Background(), "unknown"); err == nil {
+		t.Error("should error when snapshot does not exist")
+	}
+}
+
+func TestSnapshotWithInvalidName(t *testing.T) {
+	for _, name := range []string{"", "a.b", "a$b"} {
+		if err := mongotest.Snapshot(context.Background(), name); err == nil {
+			t.Errorf("should error when snapshot name is %q", name)
+		}
+	}
+}
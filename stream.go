@@ -0,0 +1,369 @@
+package mongotest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/xeipuuv/gojsonschema"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// defaultStreamBatchSize is batch size of streaming when BatchSize is not configured.
+const defaultStreamBatchSize = 1000
+
+// docReader reads fixture documents one by one.
+type docReader interface {
+	// read returns collection name, document key and document data.
+	// io.EOF is returned when all documents are read.
+	read() (string, string, DocData, error)
+	// collections returns names of collections read until now, including collections without documents.
+	collections() []string
+	Close() error
+}
+
+func openDocReader(f fixtureFile) (docReader, error) {
+	format, err := fixtureFormat(f.path)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FixtureFormatJSON:
+		file, err := openFixtureFile(f.path)
+		if err != nil {
+			return nil, err
+		}
+		return newJSONDocReader(file, f.collection), nil
+	case FixtureFormatNDJSON:
+		file, err := openFixtureFile(f.path)
+		if err != nil {
+			return nil, err
+		}
+		return newNDJSONDocReader(file, f.collectionName()), nil
+	case FixtureFormatBSON:
+		return openBSONDocReader(f)
+	}
+	ds, order, err := loadFixtureFile(f)
+	if err != nil {
+		return nil, err
+	}
+	return newDataSetDocReader(ds, order), nil
+}
+
+// jsonDocReader reads documents from JSON fixture with decoding tokens incrementally.
+type jsonDocReader struct {
+	rc      io.ReadCloser
+	dec     *json.Decoder
+	started bool
+	inColl  bool
+	coll    string
+	names   []string
+	// fixedColl is collection name when fixture holds only CollectionData of the collection.
+	fixedColl string
+}
+
+func newJSONDocReader(rc io.ReadCloser, collName string) *jsonDocReader {
+	return &jsonDocReader{rc: rc, dec: json.NewDecoder(rc), fixedColl: collName}
+}
+
+func (r *jsonDocReader) read() (string, string, DocData, error) {
+	if !r.started {
+		if err := r.expectDelim('{'); err != nil {
+			return "", "", nil, err
+		}
+		r.started = true
+		if r.fixedColl != "" {
+			r.coll, r.inColl = r.fixedColl, true
+			r.names = append(r.names, r.fixedColl)
+		}
+	}
+	for {
+		if !r.inColl {
+			if !r.dec.More() {
+				return "", "", nil, io.EOF
+			}
+			cn, err := r.readKey()
+			if err != nil {
+				return "", "", nil, err
+			}
+			if err = r.expectDelim('{'); err != nil {
+				return "", "", nil, err
+			}
+			r.coll, r.inColl = cn, true
+			r.names = append(r.names, cn)
+		}
+		if r.dec.More() {
+			key, err := r.readKey()
+			if err != nil {
+				return "", "", nil, err
+			}
+			var doc DocData
+			if err = r.dec.Decode(&doc); err != nil {
+				return "", "", nil, err
+			}
+			return r.coll, key, doc, nil
+		}
+		if err := r.expectDelim('}'); err != nil {
+			return "", "", nil, err
+		}
+		r.inColl = false
+	}
+}
+
+func (r *jsonDocReader) readKey() (string, error) {
+	tok, err := r.dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected token %v at offset %d", tok, r.dec.InputOffset())
+	}
+	return key, nil
+}
+
+func (r *jsonDocReader) expectDelim(d json.Delim) error {
+	tok, err := r.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != d {
+		return fmt.Errorf("unexpected token %v at offset %d (want %v)", tok, r.dec.InputOffset(), d)
+	}
+	return nil
+}
+
+func (r *jsonDocReader) collections() []string {
+	return r.names
+}
+
+func (r *jsonDocReader) Close() error {
+	return r.rc.Close()
+}
+
+type docEntry struct {
+	coll string
+	key  string
+	doc  DocData
+}
+
+// dataSetDocReader reads documents from DataSet that is already decoded.
+// Options and indexes entries are read before documents of each collection,
+// and documents are read in order of appearance in fixture.
+type dataSetDocReader struct {
+	entries []docEntry
+	names   []string
+}
+
+func newDataSetDocReader(ds DataSet, order dataOrder) *dataSetDocReader {
+	names := make([]string, 0, len(ds))
+	for cn := range ds {
+		names = append(names, cn)
+	}
+	sort.Strings(names)
+	var entries []docEntry
+	for _, cn := range names {
+		cd := ds[cn]
+		for _, key := range []string{optionsKey, indexesKey} {
+			if doc, ok := cd[key]; ok {
+				entries = append(entries, docEntry{coll: cn, key: key, doc: doc})
+			}
+		}
+		for _, key := range orderedKeys(cd, order[cn], DocumentOrderFile) {
+			if !isReservedKey(key) {
+				entries = append(entries, docEntry{coll: cn, key: key, doc: cd[key]})
+			}
+		}
+	}
+	return &dataSetDocReader{entries: entries, names: names}
+}
+
+func (r *dataSetDocReader) read() (string, string, DocData, error) {
+	if len(r.entries) == 0 {
+		return "", "", nil, io.EOF
+	}
+	e := r.entries[0]
+	r.entries = r.entries[1:]
+	return e.coll, e.key, e.doc, nil
+}
+
+func (r *dataSetDocReader) collections() []string {
+	return r.names
+}
+
+func (r *dataSetDocReader) Close() error {
+	return nil
+}
+
+func isReservedKey(key string) bool {
+	return key == optionsKey || key == indexesKey
+}
+
+// streamCollection is state of collection that documents are streamed into.
+type streamCollection struct {
+	collection *mongo.Collection
+	opts       CollectionOptions
+	schema     *gojsonschema.Schema
+	prepared   bool
+	batch      []fixtureDoc
+}
+
+// streamFixtures inserts documents incrementally while reading fixture files.
+// Same collection in multiple files is prepared (dropped or truncated) only once,
+// so documents of later files are appended (or upserted in LoadModeUpsert) without merging.
+// Configured timeout is applied to each operation (preparing collection, writing batch and creating indexes),
+// because streaming large fixture takes long time.
+func streamFixtures(ctx context.Context, opts LoadOptions, files ...fixtureFile) error {
+	_, client, cancel, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	db := client.Database(conf.Database)
+	colls := make(map[string]*streamCollection)
+	for _, file := range files {
+		if err = streamFile(ctx, db, opts, file, colls); err != nil {
+			return err
+		}
+	}
+	loaded := make(map[string]bool, len(colls))
+	for cn, sc := range colls {
+		if err = sc.prepare(ctx, opts.LoadMode); err != nil {
+			return err
+		}
+		if err = sc.flush(ctx, opts.LoadMode); err != nil {
+			return err
+		}
+		if err = sc.createIndexes(ctx); err != nil {
+			return err
+		}
+		loaded[cn] = true
+	}
+	markFixtureCollections(loaded)
+	return cleanCollections(ctx, opts.CleanMode, loaded)
+}
+
+func streamFile(ctx context.Context, db *mongo.Database, opts LoadOptions, f fixtureFile, colls map[string]*streamCollection) error {
+	file := f.path
+	r, err := openDocReader(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for {
+		cn, key, doc, err := r.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return withLocation(err, file, "", "")
+		}
+		if err = checkCollectionKey(f, key, doc); err != nil {
+			return err
+		}
+		sc, err := streamCollectionOf(db, cn, colls)
+		if err != nil {
+			return err
+		}
+		if err = sc.add(ctx, opts, file, key, doc); err != nil {
+			return withLocation(err, file, cn, key)
+		}
+	}
+	// Collections without documents are also loaded (dropped or truncated).
+	for _, cn := range r.collections() {
+		if _, err := streamCollectionOf(db, cn, colls); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func streamCollectionOf(db *mongo.Database, collName string, colls map[string]*streamCollection) (*streamCollection, error) {
+	if sc, ok := colls[collName]; ok {
+		return sc, nil
+	}
+	schema, err := loadSchema(collName)
+	if err != nil {
+		return nil, err
+	}
+	sc := &streamCollection{
+		collection: db.Collection(collName),
+		opts:       conf.CollectionOptions[collName],
+		schema:     schema,
+	}
+	colls[collName] = sc
+	return sc, nil
+}
+
+func (sc *streamCollection) add(ctx context.Context, opts LoadOptions, file string, key string, doc DocData) error {
+	switch key {
+	case optionsKey:
+		if sc.prepared {
+			return fmt.Errorf("%s must be written before documents in streaming mode", optionsKey)
+		}
+		collOpts, err := toCollectionOptions(doc)
+		if err != nil {
+			return err
+		}
+		sc.opts = sc.opts.merge(collOpts)
+		return nil
+	case indexesKey:
+		indexes, err := extractIndexes(CollectionData{indexesKey: doc})
+		if err != nil {
+			return err
+		}
+		sc.opts = sc.opts.merge(CollectionOptions{Indexes: indexes})
+		return nil
+	}
+	if err := sc.prepare(ctx, opts.LoadMode); err != nil {
+		return err
+	}
+	data, err := toDocData(sc.collection.Name(), key, doc, opts.PreInsertFuncs)
+	if err != nil {
+		return err
+	}
+	fd := fixtureDoc{key: key, file: file, data: data}
+	if sc.schema != nil {
+		if err = validateSchema(sc.schema, sc.collection.Name(), fd); err != nil {
+			return err
+		}
+	}
+	sc.batch = append(sc.batch, fd)
+	if len(sc.batch) >= streamBatchSize() {
+		return sc.flush(ctx, opts.LoadMode)
+	}
+	return nil
+}
+
+func (sc *streamCollection) prepare(ctx context.Context, mode LoadModeType) error {
+	if sc.prepared {
+		return nil
+	}
+	sc.prepared = true
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	return prepareCollection(ctx, sc.collection, mode, sc.opts)
+}
+
+func (sc *streamCollection) flush(ctx context.Context, mode LoadModeType) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	err := writeBatch(ctx, sc.collection, mode, sc.batch)
+	sc.batch = sc.batch[:0]
+	return err
+}
+
+func (sc *streamCollection) createIndexes(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	return createIndexes(ctx, sc.collection, sc.opts)
+}
+
+func streamBatchSize() int {
+	if conf.BatchSize > 0 {
+		return conf.BatchSize
+	}
+	return defaultStreamBatchSize
+}
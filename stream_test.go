@@ -0,0 +1,77 @@
+package mongotest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestUseFixtureWithBatchSize(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		BatchSize:       1,
+		UnorderedInsert: true,
+	})()
+	err := mongotest.UseFixture("admin_users", "foo_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cnt, err := mongotest.CountInt("users")
+	if err != nil {
+		t.Error(err)
+	}
+	if cnt != 3 {
+		t.Errorf("saved user count is invalid (want: %d, got: %d)", 3, cnt)
+	}
+}
+
+func TestUseFixtureWithStream(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		Stream:    true,
+		BatchSize: 1,
+	})()
+	for _, name := range []string{"json/admin_users", "yaml/admin_users"} {
+		t.Run(name, func(t *testing.T) {
+			err := mongotest.UseFixture(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cnt, err := mongotest.CountInt("users")
+			if err != nil {
+				t.Error(err)
+			}
+			if cnt != 2 {
+				t.Errorf("saved user count is invalid (want: %d, got: %d)", 2, cnt)
+			}
+			saved, err := mongotest.Find("users", "admin1")
+			if err != nil {
+				t.Error(err)
+			}
+			if got := saved["created_at"]; got != createdAtPrimitive {
+				t.Errorf("PreInsertFuncs should be applied (want: %v, got: %v)", createdAtPrimitive, got)
+			}
+		})
+	}
+}
+
+func TestUseFixtureWithStreamAndUpsertMode(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		Stream: true,
+	})()
+	err := mongotest.UseFixture("json/admin_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := mongotest.LoadOptions{LoadMode: mongotest.LoadModeUpsert}
+	err = mongotest.UseFixtureWithOptions(context.Background(), opts, "json/foo_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cnt, err := mongotest.CountInt("users")
+	if err != nil {
+		t.Error(err)
+	}
+	if cnt != 3 {
+		t.Errorf("saved user count is invalid (want: %d, got: %d)", 3, cnt)
+	}
+}
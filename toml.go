@@ -0,0 +1,76 @@
+package mongotest
+
+import (
+	"errors"
+
+	"github.com/BurntSushi/toml"
+)
+
+// readTOMLDataSet decodes TOML fixture with keeping order of documents.
+// Each document is written as table `[<collection name>.<document key>]`.
+// When collName is not empty, fixture is decoded as CollectionData of the collection. (table `[<document key>]`)
+func readTOMLDataSet(bs []byte, collName string) (DataSet, dataOrder, error) {
+	var m map[string]interface{}
+	md, err := toml.Decode(string(bs), &m)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := md.Keys()
+	if collName != "" {
+		m = map[string]interface{}{collName: m}
+		for i, k := range keys {
+			keys[i] = append(toml.Key{collName}, k...)
+		}
+	}
+	ds := make(DataSet, len(m))
+	for cn, v := range m {
+		docs, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, nil, &FixtureError{Collection: cn, Err: errors.New("invalid collection data")}
+		}
+		cd := make(CollectionData, len(docs))
+		for key, v := range docs {
+			doc, ok := normalizeTOMLValue(v).(map[string]interface{})
+			if !ok {
+				return nil, nil, &FixtureError{Collection: cn, Key: key, Err: errors.New("invalid document data")}
+			}
+			cd[key] = doc
+		}
+		ds[cn] = cd
+	}
+	// Keys of metadata are listed in order of appearance, and key of document has two parts.
+	order := make(dataOrder, len(ds))
+	seen := make(map[[2]string]bool)
+	for _, k := range keys {
+		if len(k) != 2 || seen[[2]string{k[0], k[1]}] {
+			continue
+		}
+		seen[[2]string{k[0], k[1]}] = true
+		order[k[0]] = append(order[k[0]], k[1])
+	}
+	return ds, order, nil
+}
+
+// normalizeTOMLValue converts array of tables into []interface{} like other formats.
+func normalizeTOMLValue(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		for k, v := range tv {
+			tv[k] = normalizeTOMLValue(v)
+		}
+		return tv
+	case []map[string]interface{}:
+		a := make([]interface{}, len(tv))
+		for i, v := range tv {
+			a[i] = normalizeTOMLValue(v)
+		}
+		return a
+	case []interface{}:
+		for i, v := range tv {
+			tv[i] = normalizeTOMLValue(v)
+		}
+		return tv
+	default:
+		return v
+	}
+}
@@ -0,0 +1,154 @@
+package mongotest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// loadedState is state of collection just after loading fixture.
+type loadedState struct {
+	// fixtureHash is hash of loaded documents, collection options and load mode.
+	fixtureHash string
+	// dbHash is hash of collection contents calculated by server (dbHash command).
+	dbHash string
+}
+
+// loadedCollections holds state of collections loaded with SkipUnchanged.
+//
+//	key: `<database>.<collection>`
+//	value: state just after loading
+var loadedCollections = struct {
+	sync.Mutex
+	states map[string]loadedState
+}{states: make(map[string]loadedState)}
+
+func loadedKey(collName string) string {
+	return conf.Database + "." + collName
+}
+
+// skipUnchangedCollections removes collections from docsMap that are loaded with same fixture
+// and not modified after loading, and returns fixture hashes of remaining collections.
+func skipUnchangedCollections(ctx context.Context, mode LoadModeType, optsMap map[string]CollectionOptions, docsMap map[string][]fixtureDoc) (map[string]string, error) {
+	hashes := make(map[string]string, len(docsMap))
+	for cn, docs := range docsMap {
+		hashes[cn] = fixtureHash(mode, optsMap[cn], docs)
+	}
+	if mode == LoadModeAppend {
+		return hashes, nil
+	}
+	var candidates []string
+	loadedCollections.Lock()
+	for cn, h := range hashes {
+		if st, ok := loadedCollections.states[loadedKey(cn)]; ok && st.fixtureHash == h {
+			candidates = append(candidates, cn)
+		}
+	}
+	loadedCollections.Unlock()
+	if len(candidates) == 0 {
+		return hashes, nil
+	}
+	dbHashes, err := collectionHashes(ctx, candidates)
+	if err != nil {
+		warnf("cannot check modification of collections, all collections are reloaded: %s", err)
+		return hashes, nil
+	}
+	loadedCollections.Lock()
+	defer loadedCollections.Unlock()
+	for _, cn := range candidates {
+		if dh, ok := dbHashes[cn]; ok && dh == loadedCollections.states[loadedKey(cn)].dbHash {
+			delete(docsMap, cn)
+			delete(hashes, cn)
+		}
+	}
+	return hashes, nil
+}
+
+// recordLoadedCollections records state of loaded collections.
+// When loading is failed, states of collections are forgotten.
+func recordLoadedCollections(ctx context.Context, hashes map[string]string, loadErr error) error {
+	names := make([]string, 0, len(hashes))
+	for cn := range hashes {
+		names = append(names, cn)
+	}
+	var dbHashes map[string]string
+	if loadErr == nil && len(names) > 0 {
+		var err error
+		dbHashes, err = collectionHashes(ctx, names)
+		if err != nil {
+			warnf("cannot record state of loaded collections: %s", err)
+		}
+	}
+	loadedCollections.Lock()
+	defer loadedCollections.Unlock()
+	for _, cn := range names {
+		dh, ok := dbHashes[cn]
+		if !ok {
+			delete(loadedCollections.states, loadedKey(cn))
+			continue
+		}
+		loadedCollections.states[loadedKey(cn)] = loadedState{fixtureHash: hashes[cn], dbHash: dh}
+	}
+	return loadErr
+}
+
+// collectionHashes returns hashes of collection contents calculated by dbHash command.
+// Collections that do not exist are not contained in result.
+func collectionHashes(ctx context.Context, names []string) (map[string]string, error) {
+	ctx, client, cancel, err := connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	var res struct {
+		Collections map[string]string `bson:"collections"`
+	}
+	cmd := bson.D{{Key: "dbHash", Value: 1}, {Key: "collections", Value: names}}
+	err = client.Database(conf.Database).RunCommand(ctx, cmd).Decode(&res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Collections, nil
+}
+
+func fixtureHash(mode LoadModeType, opts CollectionOptions, docs []fixtureDoc) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%v\n%s\n%s\n", mode, opts.Validator, opts.ValidationLevel, opts.ValidationAction)
+	for _, idx := range opts.Indexes {
+		fmt.Fprintf(h, "%v\n", idx.Keys)
+		writeValue(h, reflect.ValueOf(idx.Options))
+		fmt.Fprintln(h)
+	}
+	for _, doc := range docs {
+		fmt.Fprintf(h, "%s\n%v\n", doc.key, doc.data)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeValue writes value with dereferencing pointers, because index options are held as pointers.
+func writeValue(w io.Writer, v reflect.Value) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			fmt.Fprint(w, "<nil>")
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		fmt.Fprintf(w, "%v", v.Interface())
+		return
+	}
+	fmt.Fprint(w, "{")
+	for i := 0; i < v.NumField(); i++ {
+		fmt.Fprintf(w, "%s:", v.Type().Field(i).Name)
+		writeValue(w, v.Field(i))
+		fmt.Fprint(w, " ")
+	}
+	fmt.Fprint(w, "}")
+}
@@ -0,0 +1,93 @@
+package mongotest_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func insertUser(t *testing.T, id string) {
+	t.Helper()
+	ctx, db := connectDatabase(t)
+	if _, err := db.Collection("users").InsertOne(ctx, bson.M{"_id": id}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeState is state of users collection for checking that collection is not reloaded.
+type writeState struct {
+	inserts int64
+	deletes int64
+	uuid    string
+}
+
+func usersWriteState(t *testing.T) writeState {
+	t.Helper()
+	ctx, db := connectDatabase(t)
+	var status struct {
+		Opcounters struct {
+			Insert int64 `bson:"insert"`
+			Delete int64 `bson:"delete"`
+		} `bson:"opcounters"`
+	}
+	if err := db.Client().Database("admin").RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}}).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	specs, err := db.ListCollectionSpecifications(ctx, bson.M{"name": "users"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := writeState{inserts: status.Opcounters.Insert, deletes: status.Opcounters.Delete}
+	if len(specs) > 0 && specs[0].UUID != nil {
+		st.uuid = hex.EncodeToString(specs[0].UUID.Data)
+	}
+	return st
+}
+
+func assertUserCount(t *testing.T, want int) {
+	t.Helper()
+	cnt, err := mongotest.CountInt("users")
+	if err != nil {
+		t.Error(err)
+	}
+	if cnt != want {
+		t.Errorf("saved user count is invalid (want: %d, got: %d)", want, cnt)
+	}
+}
+
+func TestUseFixtureWithSkipUnchanged(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		SkipUnchanged: true,
+	})()
+	if err := mongotest.UseFixture("admin_users"); err != nil {
+		t.Fatal(err)
+	}
+	assertUserCount(t, 2)
+
+	before := usersWriteState(t)
+	if err := mongotest.UseFixture("admin_users"); err != nil {
+		t.Fatal(err)
+	}
+	if after := usersWriteState(t); after != before {
+		t.Errorf("unchanged collection should not be dropped or inserted (before: %+v, after: %+v)", before, after)
+	}
+	assertUserCount(t, 2)
+
+	before = usersWriteState(t)
+	insertUser(t, "modified")
+	if err := mongotest.UseFixture("admin_users"); err != nil {
+		t.Fatal(err)
+	}
+	if after := usersWriteState(t); after.inserts-before.inserts < 3 {
+		t.Errorf("modified collection should be reloaded (before: %+v, after: %+v)", before, after)
+	}
+	assertUserCount(t, 2)
+
+	if err := mongotest.UseFixture("admin_users", "foo_users"); err != nil {
+		t.Fatal(err)
+	}
+	assertUserCount(t, 3)
+}
@@ -0,0 +1,162 @@
+package mongotest
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// loadCollections writes documents of all collections into database.
+// When Transaction is configured and server supports transaction, all collections are loaded in a transaction.
+func loadCollections(ctx context.Context, mode LoadModeType, optsMap map[string]CollectionOptions, docsMap map[string][]fixtureDoc) error {
+	if conf.Transaction {
+		ok, err := supportsTransaction(ctx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return loadCollectionsInTransaction(ctx, mode, optsMap, docsMap)
+		}
+		warnf("server does not support transaction (not replica set nor sharded cluster), fixture is loaded without transaction")
+	}
+	return loadCollectionsConcurrently(ctx, mode, optsMap, docsMap)
+}
+
+func supportsTransaction(ctx context.Context) (bool, error) {
+	ctx, client, cancel, err := connect(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer cancel()
+	var res struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err = client.Database("admin").RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&res)
+	if err != nil {
+		return false, err
+	}
+	return res.SetName != "" || res.Msg == "isdbgrid", nil
+}
+
+// loadCollectionsInTransaction loads all collections in a multi-document transaction.
+// Collections are created before transaction because collection cannot be dropped in transaction,
+// so LoadModeDrop deletes all documents like LoadModeTruncate in transaction.
+// Validator of existing collection is replaced by collMod instead of recreating collection.
+// When transaction is failed, created collections are dropped and replaced validators are restored.
+// Indexes are created after the transaction is committed.
+func loadCollectionsInTransaction(ctx context.Context, mode LoadModeType, optsMap map[string]CollectionOptions, docsMap map[string][]fixtureDoc) error {
+	ctx, client, cancel, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	db := client.Database(conf.Database)
+	names := sortedCollectionNames(docsMap)
+	var changes []collectionChange
+	for _, name := range names {
+		change, err := ensureCollectionOptions(ctx, db.Collection(name), optsMap[name])
+		if change != nil {
+			changes = append(changes, *change)
+		}
+		if err != nil {
+			return undoCollectionChanges(ctx, db, changes, err)
+		}
+	}
+	sess, err := client.StartSession()
+	if err != nil {
+		return undoCollectionChanges(ctx, db, changes, err)
+	}
+	defer sess.EndSession(ctx)
+	_, err = sess.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		for _, name := range names {
+			collection := db.Collection(name)
+			if mode == LoadModeDrop || mode == LoadModeTruncate {
+				if _, err := collection.DeleteMany(sc, bson.M{}); err != nil {
+					return nil, err
+				}
+			}
+			if err := writeDocs(sc, collection, mode, docsMap[name]); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return undoCollectionChanges(ctx, db, changes, err)
+	}
+	for _, name := range names {
+		if err = createIndexes(ctx, db.Collection(name), optsMap[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectionChange is change of collection made before transaction.
+type collectionChange struct {
+	name string
+	// created is true when collection is created, otherwise validator of existing collection is replaced.
+	created bool
+	// options are validator options of existing collection before replacing.
+	options bson.Raw
+}
+
+// ensureCollectionOptions creates collection with options, or modifies validator of existing collection.
+// Change of collection is returned for undoing it.
+func ensureCollectionOptions(ctx context.Context, collection *mongo.Collection, opts CollectionOptions) (*collectionChange, error) {
+	specs, err := collection.Database().ListCollectionSpecifications(ctx, bson.M{"name": collection.Name()})
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		if err = collection.Database().CreateCollection(ctx, collection.Name(), opts.createOptions()); err != nil {
+			return nil, err
+		}
+		return &collectionChange{name: collection.Name(), created: true}, nil
+	}
+	if !opts.needsCreate() {
+		return nil, nil
+	}
+	cmd := bson.D{{Key: "collMod", Value: collection.Name()}}
+	if opts.Validator != nil {
+		cmd = append(cmd, bson.E{Key: "validator", Value: opts.Validator})
+	}
+	if opts.ValidationLevel != "" {
+		cmd = append(cmd, bson.E{Key: "validationLevel", Value: opts.ValidationLevel})
+	}
+	if opts.ValidationAction != "" {
+		cmd = append(cmd, bson.E{Key: "validationAction", Value: opts.ValidationAction})
+	}
+	if err = collection.Database().RunCommand(ctx, cmd).Err(); err != nil {
+		return nil, err
+	}
+	return &collectionChange{name: collection.Name(), options: specs[0].Options}, nil
+}
+
+// undoCollectionChanges drops created collections and restores validators of modified collections,
+// and returns given error with errors of undoing.
+func undoCollectionChanges(ctx context.Context, db *mongo.Database, changes []collectionChange, err error) error {
+	errs := MultiError{}.add(err)
+	for _, c := range changes {
+		if c.created {
+			errs = errs.add(db.Collection(c.name).Drop(ctx))
+			continue
+		}
+		// Server defaults are set when collection had no validator.
+		cmd := bson.D{
+			{Key: "collMod", Value: c.name},
+			{Key: "validator", Value: bson.M{}},
+			{Key: "validationLevel", Value: "strict"},
+			{Key: "validationAction", Value: "error"},
+		}
+		for i, key := range []string{"validator", "validationLevel", "validationAction"} {
+			if v, lerr := c.options.LookupErr(key); lerr == nil {
+				cmd[i+1].Value = v
+			}
+		}
+		errs = errs.add(db.RunCommand(ctx, cmd).Err())
+	}
+	return errs.err()
+}
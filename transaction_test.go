@@ -0,0 +1,92 @@
+package mongotest_test
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/pinzolo/mongotest"
+)
+
+// requireTransaction skips test when server is standalone, because fixture is loaded without transaction.
+func requireTransaction(t *testing.T) {
+	t.Helper()
+	ctx, db := connectDatabase(t)
+	var res struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := db.Client().Database("admin").RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if res.SetName == "" && res.Msg != "isdbgrid" {
+		t.Skip("server is standalone, transaction is not supported")
+	}
+}
+
+func TestUseFixtureWithTransaction(t *testing.T) {
+	requireTransaction(t)
+	defer mongotest.Reconfigure(mongotest.Config{
+		Transaction: true,
+	})()
+	err := mongotest.UseFixture("admin_users", "foo_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cnt, err := mongotest.CountInt("users")
+	if err != nil {
+		t.Error(err)
+	}
+	if cnt != 3 {
+		t.Errorf("saved user count is invalid (want: %d, got: %d)", 3, cnt)
+	}
+}
+
+func TestUseFixtureWithTransactionRollback(t *testing.T) {
+	requireTransaction(t)
+	defer mongotest.Reconfigure(mongotest.Config{
+		Transaction: true,
+	})()
+	if err := mongotest.UseFixture("admin_users"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, db := connectDatabase(t)
+	if _, err := db.Collection("others").InsertOne(ctx, bson.M{"_id": "other1"}); err != nil {
+		t.Fatal(err)
+	}
+	// users is loaded after companies, and its document violates validator.
+	opts := mongotest.LoadOptions{CleanMode: mongotest.CleanModeDrop}
+	if err := mongotest.UseFixtureWithOptions(ctx, opts, "transaction/invalid_users"); err == nil {
+		t.Fatal("should error when document violates validator")
+	}
+	for name, want := range map[string]int{"users": 2, "companies": 2} {
+		cnt, err := mongotest.CountInt(name)
+		if err != nil {
+			t.Error(err)
+		}
+		if cnt != want {
+			t.Errorf("%s should not be changed (want: %d, got: %d)", name, want, cnt)
+		}
+	}
+	specs, err := db.ListCollectionSpecifications(ctx, bson.M{"name": bson.M{"$in": bson.A{"users", "others"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 2 {
+		t.Fatalf("collection not in fixture should not be cleaned (got: %d collections)", len(specs))
+	}
+	for _, spec := range specs {
+		if spec.Name != "users" {
+			continue
+		}
+		var collOpts struct {
+			Validator bson.M `bson:"validator"`
+		}
+		if err = bson.Unmarshal(spec.Options, &collOpts); err != nil {
+			t.Fatal(err)
+		}
+		if len(collOpts.Validator) > 0 {
+			t.Errorf("validator of users should be restored (got: %v)", collOpts.Validator)
+		}
+	}
+}
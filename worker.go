@@ -0,0 +1,43 @@
+package mongotest
+
+import (
+	"context"
+	"sync"
+)
+
+// loadCollectionsConcurrently loads collections with configured number of workers.
+// Errors of all workers are aggregated, and no more collection is loaded after context is done.
+func loadCollectionsConcurrently(ctx context.Context, mode LoadModeType, optsMap map[string]CollectionOptions, docsMap map[string][]fixtureDoc) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs MultiError
+	)
+	addErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}
+	sem := make(chan struct{}, conf.Workers)
+	for _, cn := range sortedCollectionNames(docsMap) {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		// select chooses randomly when both cases are ready, so context is checked after waiting a free worker.
+		if err := ctx.Err(); err != nil {
+			addErr(err)
+			break
+		}
+		wg.Add(1)
+		go func(cn string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := resetCollection(ctx, cn, mode, optsMap[cn], docsMap[cn]); err != nil {
+				addErr(err)
+			}
+		}(cn)
+	}
+	wg.Wait()
+	return errs.err()
+}
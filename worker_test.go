@@ -0,0 +1,39 @@
+package mongotest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pinzolo/mongotest"
+)
+
+func TestUseFixtureWithWorkers(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		Workers: 4,
+	})()
+	err := mongotest.UseFixture("admin_users", "foo_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]int{"users": 3, "companies": 2} {
+		cnt, err := mongotest.CountInt(name)
+		if err != nil {
+			t.Error(err)
+		}
+		if cnt != want {
+			t.Errorf("saved %s count is invalid (want: %d, got: %d)", name, want, cnt)
+		}
+	}
+}
+
+func TestUseFixtureWithCanceledContext(t *testing.T) {
+	defer mongotest.Reconfigure(mongotest.Config{
+		Workers: 4,
+	})()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := mongotest.UseFixtureWithContext(ctx, "admin_users")
+	if err == nil {
+		t.Error("should error when context is canceled")
+	}
+}